@@ -0,0 +1,265 @@
+package acl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("acl", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client acl [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "       s3-client acl -recursive -set <acl> [flags] s3://bucket[/prefix]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show the grants on an object, or replace them with a canned ACL or a full")
+	fmt.Fprintln(os.Stderr, "policy from a JSON file. The JSON printed by -show -json is what -file accepts.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client acl s3://my-bucket/file.txt")
+	fmt.Fprintln(os.Stderr, "  s3-client acl -set public-read s3://my-bucket/file.txt")
+	fmt.Fprintln(os.Stderr, "  s3-client acl -show -json s3://my-bucket/file.txt > acl.json")
+	fmt.Fprintln(os.Stderr, "  s3-client acl -file acl.json s3://my-bucket/other.txt")
+	fmt.Fprintln(os.Stderr, "  s3-client acl -recursive -set public-read s3://my-site/")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	set := fs.String("set", "", "Canned ACL to apply (private, public-read, public-read-write, authenticated-read, bucket-owner-full-control)")
+	file := fs.String("file", "", "Apply the owner and grants in this JSON file (as printed by -show -json)")
+	show := fs.Bool("show", false, "Show the object's grants (the default when nothing is being set)")
+	recursive := fs.Bool("recursive", false, "With -set, apply the ACL to every object under the prefix")
+	concurrency := fs.Int("concurrency", 10, "Number of objects updated in parallel with -recursive")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *set != "" && *file != "" {
+		fmt.Fprintln(os.Stderr, "Error: -set and -file cannot be combined")
+		return 1
+	}
+	if *show && (*set != "" || *file != "") {
+		fmt.Fprintln(os.Stderr, "Error: -show cannot be combined with -set or -file")
+		return 1
+	}
+	if *recursive && *set == "" {
+		fmt.Fprintln(os.Stderr, "Error: -recursive requires -set")
+		return 1
+	}
+	if *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -concurrency must be at least 1")
+		return 1
+	}
+
+	var bucket, key string
+	var err error
+	if *recursive {
+		bucket, key, err = s3uri.ParsePrefix(fs.Arg(0))
+	} else {
+		bucket, key, err = s3uri.Parse(fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var canned types.ObjectCannedACL
+	if *set != "" {
+		canned, err = s3ops.ParseCannedACL(*set)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	var policy *types.AccessControlPolicy
+	if *file != "" {
+		policy, err = readPolicy(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if *recursive {
+		return applyRecursive(ctx, client, opts, bucket, key, canned, *concurrency)
+	}
+
+	if canned != "" {
+		if err := s3ops.PutObjectACL(ctx, client, bucket, key, canned); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("ACL %s applied to s3://%s/%s\n", canned, bucket, key)
+		return 0
+	}
+
+	if policy != nil {
+		if err := s3ops.PutObjectACLPolicy(ctx, client, bucket, key, policy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("ACL from %s applied to s3://%s/%s (%d grants)\n", *file, bucket, key, len(policy.Grants))
+		return 0
+	}
+
+	policy, err = s3ops.GetObjectACL(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(policy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	printACL(policy)
+	return 0
+}
+
+// readPolicy decodes an AccessControlPolicy from path and validates it, so
+// mistakes are reported before anything is sent.
+func readPolicy(path string) (*types.AccessControlPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL file: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var policy types.AccessControlPolicy
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("invalid ACL file %s: %w", path, err)
+	}
+	if err := s3ops.ValidateACLPolicy(&policy); err != nil {
+		return nil, fmt.Errorf("invalid ACL file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// applyRecursive sets canned on every object under prefix, concurrency at a
+// time. A failed object is reported and the rest still get the ACL; the exit
+// status is 1 if any failed.
+func applyRecursive(ctx context.Context, client *s3.Client, opts *config.Options, bucket, prefix string, canned types.ObjectCannedACL, concurrency int) int {
+	objects, err := s3ops.ListObjectsAll(ctx, client, bucket, prefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	out := opts.Out()
+	var mu sync.Mutex
+	var failed []string
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, obj := range objects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := s3ops.PutObjectACL(ctx, client, bucket, key, canned)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, key)
+				fmt.Fprintf(os.Stderr, "✗ s3://%s/%s: %v\n", bucket, key, err)
+				return
+			}
+			fmt.Fprintf(out, "✓ s3://%s/%s\n", bucket, key)
+		}(obj.Key)
+	}
+	wg.Wait()
+
+	fmt.Fprintf(out, "\nACL %s applied to %d of %d objects\n", canned, len(objects)-len(failed), len(objects))
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "%d objects failed\n", len(failed))
+		return 1
+	}
+	return 0
+}
+
+func printACL(policy *types.AccessControlPolicy) {
+	if policy.Owner != nil {
+		fmt.Printf("Owner: %s\n", ownerName(policy.Owner))
+	}
+	if len(policy.Grants) == 0 {
+		fmt.Println("No grants.")
+		return
+	}
+	fmt.Println("Grants:")
+	for _, g := range policy.Grants {
+		fmt.Printf("  %-14s %s\n", g.Permission, granteeName(g.Grantee))
+	}
+}
+
+func ownerName(o *types.Owner) string {
+	if name := aws.ToString(o.DisplayName); name != "" {
+		return fmt.Sprintf("%s (%s)", name, aws.ToString(o.ID))
+	}
+	return aws.ToString(o.ID)
+}
+
+func granteeName(g *types.Grantee) string {
+	if g == nil {
+		return "-"
+	}
+	switch g.Type {
+	case types.TypeGroup:
+		return "group " + aws.ToString(g.URI)
+	case types.TypeAmazonCustomerByEmail:
+		return "email " + aws.ToString(g.EmailAddress)
+	}
+	if name := aws.ToString(g.DisplayName); name != "" {
+		return fmt.Sprintf("user %s (%s)", name, aws.ToString(g.ID))
+	}
+	return "user " + aws.ToString(g.ID)
+}
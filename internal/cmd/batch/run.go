@@ -0,0 +1,359 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/report"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// uploadPartSize is the multipart part size for uploads; smaller files go
+// up in a single PUT.
+const uploadPartSize = 8 * 1024 * 1024
+
+const defaultConcurrency = 4
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("batch", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client batch [flags] <operations.jsonl | ->")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Run the operations listed in a JSON-lines file (or stdin with -), one per line,")
+	fmt.Fprintln(os.Stderr, "in parallel with a single client. Blank lines and lines starting with # are")
+	fmt.Fprintln(os.Stderr, "ignored. Every line is checked before anything runs.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Operations:")
+	fmt.Fprintln(os.Stderr, `  {"op":"download","src":"s3://bucket/key","dst":"local/path"}   (dst defaults to the key's base name)`)
+	fmt.Fprintln(os.Stderr, `  {"op":"upload","src":"local/file","dst":"s3://bucket/key"}     (a dst ending in / gets the file name)`)
+	fmt.Fprintln(os.Stderr, `  {"op":"copy","src":"s3://bucket/key","dst":"s3://bucket/other"}`)
+	fmt.Fprintln(os.Stderr, `  {"op":"delete","src":"s3://bucket/key"}`)
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Without -continue-on-error the first failure stops the operations still running")
+	fmt.Fprintln(os.Stderr, "or queued, and they are reported as skipped. The exit status is 1 if any")
+	fmt.Fprintln(os.Stderr, "operation failed.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client batch -concurrency 8 ops.jsonl")
+	fmt.Fprintln(os.Stderr, "  generate-ops | s3-client batch -continue-on-error -")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+// operation is one line of the operations file.
+type operation struct {
+	Op  string `json:"op"`
+	Src string `json:"src"`
+	Dst string `json:"dst,omitempty"`
+
+	line int
+}
+
+// result is the outcome of one operation. skipped is set for operations
+// that never ran because an earlier one failed.
+type result struct {
+	key     string
+	bytes   int64
+	err     error
+	skipped bool
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	concurrency := fs.Int("concurrency", defaultConcurrency, "Number of operations to run at once")
+	continueOnError := fs.Bool("continue-on-error", false, "Keep running after an operation fails and report all failures at the end")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -concurrency must be at least 1")
+		return 1
+	}
+
+	ops, err := readOperations(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(ops) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no operations to run")
+		return 1
+	}
+
+	// Ctrl+C cancels ctx so in-flight transfers stop and queued ones are
+	// skipped.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+	client := s3.NewFromConfig(cfg)
+
+	out := opts.Out()
+	start := time.Now()
+	results := runOperations(ctx, client, ops, *concurrency, *continueOnError, out)
+
+	var keys []string
+	var bytes int64
+	var failed, skipped int
+	var firstErr error
+	for i, r := range results {
+		switch {
+		case r.skipped:
+			skipped++
+		case r.err != nil:
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("line %d: %w", ops[i].line, r.err)
+			}
+		default:
+			keys = append(keys, r.key)
+			bytes += r.bytes
+		}
+	}
+
+	if opts.JSON {
+		result := report.New("batch", "", start, firstErr)
+		result.Keys = keys
+		result.Bytes = bytes
+		report.Write(os.Stdout, result)
+	}
+
+	fmt.Fprintf(out, "\n%d succeeded, %d failed, %d skipped in %s\n", len(keys), failed, skipped, ui.FormatDuration(time.Since(start)))
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Batch cancelled.")
+		return 130
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readOperations parses and checks every line of name ("-" for stdin), so
+// a typo on the last line is reported before the first operation runs.
+func readOperations(name string) ([]operation, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var ops []operation
+	var problems []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var op operation
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&op); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		op.line = line
+		if err := op.validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid operations:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return ops, nil
+}
+
+func (op operation) validate() error {
+	if op.Src == "" {
+		return errors.New("src is required")
+	}
+	switch op.Op {
+	case "download", "delete":
+		if op.Op == "delete" && op.Dst != "" {
+			return errors.New("delete takes no dst")
+		}
+		_, _, err := s3uri.Parse(op.Src)
+		return err
+	case "upload":
+		if op.Dst == "" {
+			return errors.New("upload needs dst")
+		}
+		_, _, err := s3uri.ParsePrefix(op.Dst)
+		return err
+	case "copy":
+		if _, _, err := s3uri.Parse(op.Src); err != nil {
+			return err
+		}
+		if op.Dst == "" {
+			return errors.New("copy needs dst")
+		}
+		_, _, err := s3uri.Parse(op.Dst)
+		return err
+	}
+	return fmt.Errorf("unknown op %q (want download, upload, copy or delete)", op.Op)
+}
+
+// runOperations runs ops on concurrency workers. Unless continueOnError,
+// the first failure cancels the rest: running transfers stop, and they and
+// the queued operations are marked skipped.
+func runOperations(ctx context.Context, client *s3.Client, ops []operation, concurrency int, continueOnError bool, out io.Writer) []result {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]result, len(ops))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(concurrency, len(ops)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i] = result{skipped: true}
+					continue
+				}
+				op := ops[i]
+				r := op.run(ctx, client)
+				if r.err != nil && ctx.Err() != nil && errors.Is(r.err, context.Canceled) {
+					// Stopped by an earlier failure or Ctrl+C, not a
+					// failure of its own.
+					r = result{skipped: true}
+				}
+				results[i] = r
+				if r.skipped {
+					continue
+				}
+
+				mu.Lock()
+				if r.err != nil {
+					fmt.Fprintf(os.Stderr, "✗ line %d: %s %s: %v\n", op.line, op.Op, op.Src, r.err)
+					if !continueOnError {
+						cancel()
+					}
+				} else {
+					fmt.Fprintf(out, "✓ line %d: %s\n", op.line, op.describe())
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range ops {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (op operation) describe() string {
+	if op.Dst == "" {
+		return fmt.Sprintf("%s %s", op.Op, op.Src)
+	}
+	return fmt.Sprintf("%s %s → %s", op.Op, op.Src, op.Dst)
+}
+
+// run performs the operation; Src and Dst were checked by validate.
+func (op operation) run(ctx context.Context, client *s3.Client) result {
+	switch op.Op {
+	case "download":
+		bucket, key, _ := s3uri.Parse(op.Src)
+		dst := op.Dst
+		if dst == "" {
+			dst = path.Base(key)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return result{err: fmt.Errorf("failed to create directory for %s: %w", dst, err)}
+		}
+		d := &s3ops.ChunkedDownloader{Client: client, Concurrency: s3ops.DefaultConcurrency}
+		n, err := d.DownloadFile(ctx, bucket, key, dst)
+		return result{key: key, bytes: n, err: err}
+
+	case "upload":
+		bucket, key, _ := s3uri.ParsePrefix(op.Dst)
+		if key == "" || strings.HasSuffix(key, "/") {
+			key += filepath.Base(op.Src)
+		}
+		info, err := os.Stat(op.Src)
+		if err != nil {
+			return result{err: err}
+		}
+		if !info.Mode().IsRegular() {
+			return result{err: fmt.Errorf("%s is not a regular file", op.Src)}
+		}
+		if info.Size() < uploadPartSize {
+			err = s3ops.UploadFile(ctx, client, op.Src, bucket, key, nil)
+		} else {
+			err = s3ops.UploadMultipart(ctx, client, op.Src, bucket, key, uploadPartSize, nil)
+		}
+		return result{key: key, bytes: info.Size(), err: err}
+
+	case "copy":
+		srcBucket, srcKey, _ := s3uri.Parse(op.Src)
+		dstBucket, dstKey, _ := s3uri.Parse(op.Dst)
+		copyOpts := s3ops.CopyOptions{
+			PreserveACL:  true,
+			PreserveTags: true,
+			Warn:         func(err error) { fmt.Fprintf(os.Stderr, "Warning: line %d: %v\n", op.line, err) },
+		}
+		err := s3ops.CopyObjectWithOptions(ctx, client, srcBucket, srcKey, dstBucket, dstKey, copyOpts)
+		return result{key: dstKey, err: err}
+
+	case "delete":
+		bucket, key, _ := s3uri.Parse(op.Src)
+		return result{key: key, err: s3ops.DeleteObject(ctx, client, bucket, key)}
+	}
+	return result{err: fmt.Errorf("unknown op %q", op.Op)}
+}
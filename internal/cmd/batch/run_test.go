@@ -0,0 +1,115 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func writeOps(t *testing.T, content string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "ops.jsonl")
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestReadOperations(t *testing.T) {
+	ops, err := readOperations(writeOps(t, `
+# comment
+{"op":"download","src":"s3://bucket/a.txt"}
+
+{"op":"upload","src":"b.txt","dst":"s3://bucket/dir/"}
+  {"op":"copy","src":"s3://bucket/a.txt","dst":"s3://other/a.txt"}
+{"op":"delete","src":"s3://bucket/a.txt"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, op := range ops {
+		got = append(got, fmt.Sprintf("%d:%s", op.line, op.Op))
+	}
+	if want := "3:download 5:upload 6:copy 7:delete"; strings.Join(got, " ") != want {
+		t.Errorf("operations = %v, want %s", got, want)
+	}
+}
+
+func TestReadOperationsRejectsBadLines(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"malformed JSON", `{"op":"download",`, "line 2:"},
+		{"unknown field", `{"op":"delete","src":"s3://bucket/a","recursive":true}`, "unknown field"},
+		{"unknown op", `{"op":"move","src":"s3://bucket/a","dst":"s3://bucket/b"}`, `unknown op "move"`},
+		{"missing src", `{"op":"download"}`, "src is required"},
+		{"upload without dst", `{"op":"upload","src":"a.txt"}`, "upload needs dst"},
+		{"copy without dst", `{"op":"copy","src":"s3://bucket/a"}`, "copy needs dst"},
+		{"delete with dst", `{"op":"delete","src":"s3://bucket/a","dst":"s3://bucket/b"}`, "delete takes no dst"},
+		{"download from a bucket", `{"op":"download","src":"s3://bucket"}`, "line 2:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every line is checked, so a good line before the bad one
+			// does not hide it.
+			_, err := readOperations(writeOps(t, `{"op":"delete","src":"s3://bucket/ok"}`+"\n"+tt.line+"\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("readOperations() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunOperationsSkipsCancelled checks that an operation cut short by
+// another's failure is reported as skipped, not as a second failure.
+func TestRunOperationsSkipsCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bucket/slow":
+			// Hang until the batch cancels the request.
+			<-r.Context().Done()
+		case "/bucket/bad":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "<Error><Code>AccessDenied</Code><Message>denied</Message></Error>")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer:      aws.NopRetryer{},
+	})
+
+	dir := t.TempDir()
+	ops := []operation{
+		{Op: "download", Src: "s3://bucket/slow", Dst: filepath.Join(dir, "slow"), line: 1},
+		{Op: "delete", Src: "s3://bucket/bad", line: 2},
+		{Op: "delete", Src: "s3://bucket/later", line: 3},
+	}
+	results := runOperations(t.Context(), client, ops, 2, false, io.Discard)
+
+	if !results[0].skipped {
+		t.Errorf("in-flight download: %+v, want skipped", results[0])
+	}
+	if results[1].skipped || results[1].err == nil {
+		t.Errorf("failing delete: %+v, want its error", results[1])
+	}
+	if !results[2].skipped {
+		t.Errorf("queued delete: %+v, want skipped", results[2])
+	}
+}
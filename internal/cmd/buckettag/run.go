@@ -0,0 +1,167 @@
+package buckettag
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("bucket-tag", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client bucket-tag [flags] s3://bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show, set, or delete the tags of an S3 bucket. -set merges into the existing")
+	fmt.Fprintln(os.Stderr, "tags unless -replace is given.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client bucket-tag -show s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client bucket-tag -set team=data,cost-center=1234 s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client bucket-tag -set env=prod -replace s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client bucket-tag -delete s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	set := fs.String("set", "", "Tags to apply as KEY=VALUE pairs (comma-separated)")
+	replace := fs.Bool("replace", false, "With -set, replace all existing tags instead of merging")
+	delete := fs.Bool("delete", false, "Delete all tags from the bucket")
+	show := fs.Bool("show", false, "Show the bucket's tags")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, err := s3uri.ParseBucket(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !*show && !*delete && *set == "" {
+		fmt.Fprintln(os.Stderr, "Error: Must specify one of -show, -set, or -delete")
+		fs.Usage()
+		return 1
+	}
+	if *replace && *set == "" {
+		fmt.Fprintln(os.Stderr, "Error: -replace only applies with -set")
+		return 1
+	}
+
+	var tags map[string]string
+	if *set != "" {
+		tags, err = parseTags(*set)
+		if err == nil {
+			err = s3ops.ValidateBucketTags(tags)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if *show {
+		current, err := s3ops.GetBucketTagging(ctx, client, bucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if len(current) == 0 {
+			fmt.Println("No tags set.")
+			return 0
+		}
+		printTags(current)
+		return 0
+	}
+
+	if *delete {
+		if err := s3ops.DeleteBucketTagging(ctx, client, bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Tags deleted for bucket %s\n", bucket)
+		return 0
+	}
+
+	// PutBucketTagging always replaces the whole set, so merging means
+	// reading the current tags first.
+	if !*replace {
+		current, err := s3ops.GetBucketTagging(ctx, client, bucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		for k, v := range tags {
+			current[k] = v
+		}
+		tags = current
+	}
+
+	if err := s3ops.PutBucketTagging(ctx, client, bucket, tags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Tags set for bucket %s:\n", bucket)
+	printTags(tags)
+	return 0
+}
+
+// parseTags parses "KEY=VALUE,KEY=VALUE". Values may be empty; keys may not.
+func parseTags(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid tag %q: want KEY=VALUE", pair)
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func printTags(tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %s = %s\n", k, tags[k])
+	}
+}
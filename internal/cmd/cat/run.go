@@ -0,0 +1,117 @@
+package cat
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("cat", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client cat [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Write an object's contents to stdout, for piping into other tools.")
+	fmt.Fprintln(os.Stderr, "Nothing else is printed to stdout; errors go to stderr.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client cat s3://my-bucket/config.json | jq .")
+	fmt.Fprintln(os.Stderr, "  s3-client cat s3://my-bucket/logs/app.log.gz | gunzip | grep ERROR")
+	fmt.Fprintln(os.Stderr, "  s3-client cat -range bytes=0-1023 s3://my-bucket/big.bin | xxd")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	byteRange := fs.String("range", "", "Only read this byte range: bytes=START-END, bytes=START- or bytes=-SUFFIX")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *byteRange != "" {
+		if err := validateRange(*byteRange); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	out := bufio.NewWriterSize(os.Stdout, 64*1024)
+	_, err = s3ops.StreamObject(ctx, client, bucket, key, *byteRange, out)
+	if ferr := out.Flush(); err == nil && ferr != nil {
+		err = fmt.Errorf("failed to write: %w", ferr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// validateRange checks s is a single HTTP byte range S3 will accept, so a
+// typo fails here rather than silently returning the whole object.
+func validateRange(s string) error {
+	spec, ok := strings.CutPrefix(s, "bytes=")
+	start, end, dash := strings.Cut(spec, "-")
+	if !ok || !dash || (start == "" && end == "") {
+		return fmt.Errorf("invalid -range %q: want bytes=START-END, bytes=START- or bytes=-SUFFIX", s)
+	}
+
+	var first, last int64 = 0, -1
+	var err error
+	if start != "" {
+		if first, err = strconv.ParseInt(start, 10, 64); err != nil || first < 0 {
+			return fmt.Errorf("invalid -range %q: bad start %q", s, start)
+		}
+	}
+	if end != "" {
+		if last, err = strconv.ParseInt(end, 10, 64); err != nil || last < 0 {
+			return fmt.Errorf("invalid -range %q: bad end %q", s, end)
+		}
+	}
+	if start != "" && end != "" && last < first {
+		return fmt.Errorf("invalid -range %q: end is before start", s)
+	}
+	return nil
+}
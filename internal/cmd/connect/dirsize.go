@@ -0,0 +1,107 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A directory's size is summed from at most dirSizeMaxKeys objects, within
+// dirSizeTimeout, so a huge prefix can't keep listing in the background.
+// Hitting either limit yields a lower bound.
+const (
+	dirSizeMaxKeys = 100_000
+	dirSizeTimeout = 30 * time.Second
+)
+
+// dirSize is the cached total of one prefix. truncated means the listing
+// stopped at a limit, so size and count are lower bounds.
+type dirSize struct {
+	pending   bool
+	size      int64
+	count     int
+	truncated bool
+	err       error
+}
+
+type dirSizeMsg struct {
+	key string
+	dirSize
+}
+
+// dirSizeKey identifies a prefix across buckets in the size cache.
+func dirSizeKey(bucket, prefix string) string {
+	return bucket + "/" + prefix
+}
+
+// sizeHighlightedDir starts computing the size of the highlighted directory
+// if it is not cached or already being computed. The result arrives as a
+// dirSizeMsg; sizes are kept for the session.
+func (m *model) sizeHighlightedDir() tea.Cmd {
+	if m.client == nil || m.activePane != paneObjects || m.cursorObject >= len(m.objects) {
+		return nil
+	}
+	obj := m.objects[m.cursorObject]
+	if !obj.IsDir {
+		return nil
+	}
+	bucket, prefix, client := m.bucket, m.prefix+obj.Name, m.objectClient()
+	key := dirSizeKey(bucket, prefix)
+	if _, ok := m.dirSizes[key]; ok {
+		return nil
+	}
+	m.dirSizes[key] = dirSize{pending: true}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), dirSizeTimeout)
+		defer cancel()
+		return dirSizeMsg{key: key, dirSize: prefixSize(ctx, client, bucket, prefix)}
+	}
+}
+
+func prefixSize(ctx context.Context, client *s3.Client, bucket, prefix string) dirSize {
+	var d dirSize
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		if d.count >= dirSizeMaxKeys {
+			d.truncated = true
+			break
+		}
+		page, err := paginator.NextPage(ctx)
+		if err != nil && d.count > 0 && ctx.Err() == context.DeadlineExceeded {
+			d.truncated = true
+			break
+		}
+		if err != nil {
+			d.err = fmt.Errorf("failed to list objects: %w", err)
+			return d
+		}
+		for _, obj := range page.Contents {
+			d.size += aws.ToInt64(obj.Size)
+			d.count++
+		}
+	}
+	return d
+}
+
+// String renders the size for the metadata panel.
+func (d dirSize) String() string {
+	switch {
+	case d.pending:
+		return "computing…"
+	case d.err != nil:
+		return "unavailable"
+	case d.truncated:
+		return fmt.Sprintf("≥ %s (%d+ objects)", ui.FormatSize(d.size), d.count)
+	}
+	return fmt.Sprintf("%s (%d objects)", ui.FormatSize(d.size), d.count)
+}
@@ -0,0 +1,143 @@
+package connect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"s3-client/internal/shared/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newObjectServer serves HEAD and ranged GET for the objects in content,
+// keyed by "/bucket/key", through an unsigned client without SDK retries.
+func newObjectServer(t *testing.T, content map[string][]byte) *s3.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := content[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.Write(data)
+			return
+		}
+		end = min(end, len(data)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[start : end+1])
+	}))
+	t.Cleanup(srv.Close)
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+// quitWhenDownloaded wraps the model and stops the program once the last
+// queued download has finished.
+type quitWhenDownloaded struct {
+	*model
+}
+
+func (q quitWhenDownloaded) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	_, cmd := q.model.Update(msg)
+	if _, ok := msg.(dlDoneMsg); ok && !q.downloading {
+		return q, tea.Quit
+	}
+	return q, cmd
+}
+
+// TestDownloadWhileModelChanges downloads two selected objects through a
+// running program while the user keeps navigating and listings arrive, so
+// that `go test -race` catches download commands reading the model.
+func TestDownloadWhileModelChanges(t *testing.T) {
+	a := bytes.Repeat([]byte("a"), 300_000)
+	b := bytes.Repeat([]byte("b"), 200_000)
+	client := newObjectServer(t, map[string][]byte{
+		"/bucket/dir/a.txt": a,
+		"/bucket/dir/b.txt": b,
+	})
+
+	dir := t.TempDir()
+	m := initialModel(client, config.Options{}, nil, dir, 4)
+	m.bucket = "bucket"
+	m.prefix = "dir/"
+	m.activePane = paneObjects
+	m.objects = []S3Entry{{Name: "a.txt", Size: int64(len(a))}, {Name: "b.txt", Size: int64(len(b))}}
+
+	p := tea.NewProgram(quitWhenDownloaded{&m},
+		tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutRenderer(), tea.WithoutSignalHandler())
+	m.program = p
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Run()
+		done <- err
+	}()
+
+	keyPress := func(s string) tea.KeyMsg {
+		switch s {
+		case " ":
+			return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		case "down":
+			return tea.KeyMsg{Type: tea.KeyDown}
+		case "up":
+			return tea.KeyMsg{Type: tea.KeyUp}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+	for _, k := range []string{" ", "down", " ", "D"} {
+		p.Send(keyPress(k))
+	}
+	// Keep changing the model while the downloads run.
+	for i := range 50 {
+		p.Send(keyPress([]string{"up", "down"}[i%2]))
+		p.Send(tea.WindowSizeMsg{Width: 80 + i, Height: 24})
+		p.Send(objectsMsg{{Name: fmt.Sprintf("other-%d.txt", i), Size: int64(i)}})
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(30 * time.Second):
+		p.Kill()
+		t.Fatal("downloads did not finish")
+	}
+
+	if m.dlError != nil || !strings.HasPrefix(m.dlStatus, "Successfully downloaded") {
+		t.Fatalf("dlStatus = %q, dlError = %v", m.dlStatus, m.dlError)
+	}
+	for name, want := range map[string][]byte{"a.txt": a, "b.txt": b} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: downloaded %d bytes that differ from the object", name, len(got))
+		}
+	}
+}
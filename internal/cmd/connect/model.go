@@ -3,14 +3,24 @@ package connect
 import (
 	"context"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3client"
+	"s3-client/internal/shared/ui"
+
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -27,10 +37,16 @@ const (
 	overlayNone overlay = iota
 	overlayPalette
 	overlayProperties
+	overlayCopy
+	overlayPreview
+	overlayRename
+	overlayUpload
 )
 
 type model struct {
 	client       *s3.Client
+	opts         config.Options
+	factory      *s3client.Factory
 	program      *tea.Program
 	activePane   pane
 	overlay      overlay
@@ -49,18 +65,56 @@ type model struct {
 	loading      bool
 	spinner      spinner.Model
 
-	taskHistory []string
+	taskHistory []historyEntry
+
+	// Region of each bucket opened so far, and the client for the open one.
+	regions      map[string]string
+	bucketRegion string
+	bucketClient *s3.Client
+
+	// Profile picker, shown instead of the browser until client is set.
+	profiles      []string
+	cursorProfile int
+	profileErr    error
 
 	propEntry *S3Entry
 
+	// dirSizes caches directory totals for the metadata panel, keyed by
+	// dirSizeKey.
+	dirSizes map[string]dirSize
+
+	paletteCursor int
+	copyInput     textinput.Model
+	copySource    string
+	renameInput   textinput.Model
+	renameSource  string
+
+	preview     viewport.Model
+	previewName string
+
+	// selected holds full keys in the current prefix marked with space.
+	selected map[string]bool
+
+	// downloadDir is where downloads are saved (-download-dir) and
+	// dlConcurrency the number of parallel chunk requests per download.
+	downloadDir   string
+	dlConcurrency int
+
 	downloading bool
 	dlProgress  progress.Model
 	dlName      string
+	dlPath      string
 	dlError     error
 	dlStatus    string
+	dlBucket    string
+	dlClient    *s3.Client
+	dlQueue     []string
+	dlIndex     int
+	dlTotal     int
 
 	uploading  bool
 	upProgress progress.Model
+	upInput    textinput.Model
 	upName     string
 	upError    error
 	upStatus   string
@@ -84,6 +138,10 @@ type keyMap struct {
 	Upload     key.Binding
 	Delete     key.Binding
 	Refresh    key.Binding
+	Select     key.Binding
+	DlSelected key.Binding
+	Preview    key.Binding
+	Rename     key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -94,7 +152,7 @@ func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Enter, k.Tab, k.Back},
 		{k.Home, k.End, k.PageUp, k.PageDown},
-		{k.Refresh, k.Upload, k.Delete, k.Quit},
+		{k.Select, k.DlSelected, k.Preview, k.Rename, k.Refresh, k.Upload, k.Delete, k.Quit},
 	}
 }
 
@@ -113,27 +171,41 @@ var keys = keyMap{
 	Upload:     key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "upload")),
 	Delete:     key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
 	Refresh:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+	Select:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
+	DlSelected: key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "download selected")),
+	Preview:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "preview")),
+	Rename:     key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "rename")),
 }
 
-func initialModel(client *s3.Client) model {
+func initialModel(client *s3.Client, opts config.Options, profiles []string, downloadDir string, concurrency int) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = spinnerStyle
 
 	return model{
-		client:      client,
-		activePane:  paneBuckets,
-		overlay:     overlayNone,
-		help:        help.New(),
-		keys:        keys,
-		dlProgress:  progress.New(progress.WithDefaultGradient()),
-		upProgress:  progress.New(progress.WithDefaultGradient()),
-		spinner:     s,
-		taskHistory: []string{"TUI started"},
+		client:        client,
+		opts:          opts,
+		factory:       s3client.NewFactory(),
+		regions:       make(map[string]string),
+		dirSizes:      make(map[string]dirSize),
+		profiles:      profiles,
+		downloadDir:   downloadDir,
+		dlConcurrency: concurrency,
+		activePane:    paneBuckets,
+		overlay:       overlayNone,
+		help:          help.New(),
+		keys:          keys,
+		dlProgress:    progress.New(progress.WithDefaultGradient()),
+		upProgress:    progress.New(progress.WithDefaultGradient()),
+		spinner:       s,
+		taskHistory:   []historyEntry{{kind: historyInfo, text: "TUI started"}},
 	}
 }
 
 func (m model) Init() tea.Cmd {
+	if m.client == nil {
+		return m.spinner.Tick
+	}
 	return tea.Batch(m.loadBuckets, m.spinner.Tick)
 }
 
@@ -146,7 +218,7 @@ func (m model) loadBuckets() tea.Msg {
 }
 
 func (m model) loadObjects() tea.Msg {
-	objects, err := listObjects(context.Background(), m.client, m.bucket, m.prefix)
+	objects, err := listObjects(context.Background(), m.objectClient(), m.bucket, m.prefix)
 	if err != nil {
 		return err
 	}
@@ -155,7 +227,7 @@ func (m model) loadObjects() tea.Msg {
 
 func (m model) loadMetadata(bucket, key string) tea.Cmd {
 	return func() tea.Msg {
-		meta, err := getObjectMetadata(context.Background(), m.client, bucket, key)
+		meta, err := getObjectMetadata(context.Background(), m.objectClient(), bucket, key)
 		if err != nil {
 			return err
 		}
@@ -170,11 +242,35 @@ type dlProgressMsg float64
 type dlDoneMsg struct{ err error }
 type clearStatusMsg struct{}
 
+// Update handles msg and then, whatever changed, makes sure the size of a
+// highlighted directory is known or on its way.
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	return next, tea.Batch(cmd, m.sizeHighlightedDir())
+}
+
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	paneHeight := m.getViewHeight()
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.client == nil {
+			return m.updateProfilePicker(msg)
+		}
+
+		switch m.overlay {
+		case overlayPalette:
+			return m.updatePalette(msg)
+		case overlayCopy:
+			return m.updateCopyInput(msg)
+		case overlayPreview:
+			return m.updatePreview(msg)
+		case overlayRename:
+			return m.updateRenameInput(msg)
+		case overlayUpload:
+			return m.updateUploadInput(msg)
+		}
+
 		if m.overlay != overlayNone {
 			if msg.String() == "esc" || msg.String() == "q" {
 				m.overlay = overlayNone
@@ -252,6 +348,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keys.CmdPalette):
 			m.overlay = overlayPalette
+			m.paletteCursor = 0
 			return m, nil
 
 		case key.Matches(msg, m.keys.Tab):
@@ -301,13 +398,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.activePane == paneBuckets {
 				if len(m.buckets) > 0 {
 					m.bucket = m.buckets[m.cursorBucket]
+					m.bucketRegion = ""
+					m.bucketClient = nil
+					m.objects = nil
 					m.prefix = ""
 					m.history = nil
+					m.selected = nil
 					m.activePane = paneObjects
 					m.offsetObject = 0
 					m.cursorObject = 0
 					m.loading = true
-					return m, m.loadObjects
+					return m, m.resolveBucket(m.bucket)
 				}
 			} else {
 				if len(m.objects) > 0 {
@@ -315,13 +416,19 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if obj.IsDir {
 						m.history = append(m.history, m.prefix)
 						m.prefix += obj.Name
+						m.selected = nil
 						m.cursorObject = 0
 						m.offsetObject = 0
 						m.loading = true
 						return m, m.loadObjects
-					} else {
-						m.addHistory(fmt.Sprintf("Download started: %s", obj.Name))
-						return m, m.startDownload(obj)
+					} else if len(m.selected) > 0 {
+						return m, m.startBatchDownload()
+					} else if !m.downloading {
+						m.addHistory(historyInfo, fmt.Sprintf("Download started: %s", obj.Name))
+						m.dlIndex, m.dlTotal = 1, 1
+						m.dlBucket = m.bucket
+						m.dlClient = m.objectClient()
+						return m, m.startDownload(m.prefix + obj.Name)
 					}
 				}
 			}
@@ -331,6 +438,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(m.history) > 0 {
 					m.prefix = m.history[len(m.history)-1]
 					m.history = m.history[:len(m.history)-1]
+					m.selected = nil
 					m.cursorObject = 0
 					m.offsetObject = 0
 					m.loading = true
@@ -340,6 +448,45 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case key.Matches(msg, m.keys.Select):
+			if m.activePane == paneObjects && len(m.objects) > 0 {
+				obj := m.objects[m.cursorObject]
+				if obj.IsDir {
+					m.addHistory(historyInfo, fmt.Sprintf("Select: %s is a folder; open it and select files instead", obj.Name))
+				} else {
+					k := m.prefix + obj.Name
+					if m.selected[k] {
+						delete(m.selected, k)
+					} else {
+						if m.selected == nil {
+							m.selected = make(map[string]bool)
+						}
+						m.selected[k] = true
+					}
+				}
+			}
+
+		case key.Matches(msg, m.keys.DlSelected):
+			if m.activePane == paneObjects && len(m.selected) > 0 {
+				return m, m.startBatchDownload()
+			}
+
+		case key.Matches(msg, m.keys.Preview):
+			if m.activePane == paneObjects {
+				obj, ok := m.selectedObject()
+				if !ok {
+					m.addHistory(historyInfo, "Preview: select a file first")
+					return m, nil
+				}
+				m.loading = true
+				return m, m.loadPreview(m.bucket, m.prefix+obj.Name, obj.Name)
+			}
+
+		case key.Matches(msg, m.keys.Rename):
+			if m.activePane == paneObjects {
+				return m, m.startRename()
+			}
+
 		case key.Matches(msg, m.keys.Refresh):
 			m.loading = true
 			if m.activePane == paneBuckets || m.bucket == "" {
@@ -348,17 +495,69 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.loadObjects
 
 		case key.Matches(msg, m.keys.Upload):
-			if m.bucket != "" {
-				m.addHistory("Upload: Use CLI 's3-client upload' command")
-			}
+			return m, m.startUploadInput()
 
 		case key.Matches(msg, m.keys.Delete):
 			if m.activePane == paneObjects && len(m.objects) > 0 {
 				obj := m.objects[m.cursorObject]
-				m.addHistory(fmt.Sprintf("Delete: Use CLI to delete s3://%s/%s%s", m.bucket, m.prefix, obj.Name))
+				m.addHistory(historyInfo, fmt.Sprintf("Delete: Use CLI to delete s3://%s/%s%s", m.bucket, m.prefix, obj.Name))
 			}
 		}
 
+	case clientMsg:
+		m.client = msg.client
+		m.opts.Profile = msg.profile
+		m.loading = true
+		m.addHistory(historySuccess, fmt.Sprintf("Connected with profile %s", msg.profile))
+		return m, m.loadBuckets
+
+	case profileErrMsg:
+		m.profileErr = msg.err
+		m.loading = false
+		return m, nil
+
+	case dirSizeMsg:
+		m.dirSizes[msg.key] = msg.dirSize
+		return m, nil
+
+	case bucketRegionMsg:
+		if msg.bucket != m.bucket {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.addHistory(historyError, fmt.Sprintf("Region lookup for %s failed: %v", msg.bucket, msg.err))
+		}
+		if msg.region != "" {
+			m.regions[msg.bucket] = msg.region
+		}
+		m.bucketRegion = msg.region
+		m.bucketClient = msg.client
+		return m, m.loadObjects
+
+	case copyDoneMsg:
+		for _, w := range msg.warnings {
+			m.addHistory(historyInfo, "Warning: "+w)
+		}
+		if msg.err != nil {
+			m.addHistory(historyError, fmt.Sprintf("Copy failed: %s → %s: %v", msg.src, msg.dst, msg.err))
+			return m, nil
+		}
+		m.addHistory(historySuccess, fmt.Sprintf("Copied %s → %s", msg.src, msg.dst))
+		m.loading = true
+		return m, m.loadObjects
+
+	case renameDoneMsg:
+		for _, w := range msg.warnings {
+			m.addHistory(historyInfo, "Warning: "+w)
+		}
+		if msg.err != nil {
+			m.addHistory(historyError, fmt.Sprintf("Rename failed: %s → %s: %v", msg.src, msg.dst, msg.err))
+			return m, nil
+		}
+		m.addHistory(historySuccess, fmt.Sprintf("Renamed %s → %s", msg.src, msg.dst))
+		m.loading = true
+		return m, m.loadObjects
+
 	case bucketsMsg:
 		m.buckets = msg
 		m.loading = false
@@ -367,6 +566,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.objects = msg
 		m.loading = false
 
+	case previewMsg:
+		m.openPreview(msg)
+		return m, nil
+
 	case propsMsg:
 		m.propEntry = msg.meta
 		m.overlay = overlayProperties
@@ -381,10 +584,15 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		m.help.Width = msg.Width
+		m.dlProgress.Width = max(m.bottomColWidth()-4, 10)
+		m.upProgress.Width = m.dlProgress.Width
+		if m.overlay == overlayPreview {
+			m.preview.Width, m.preview.Height = m.previewSize()
+		}
 		return m, nil
 
 	case dlProgressMsg:
-		cmd := m.dlProgress.SetPercent(float64(msg))
+		cmd := setPercent(&m.dlProgress, float64(msg))
 		return m, cmd
 
 	case dlDoneMsg:
@@ -392,10 +600,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.dlError = msg.err
 		if msg.err != nil {
 			m.dlStatus = fmt.Sprintf("Error downloading %s: %v", m.dlName, msg.err)
+			m.addHistory(historyError, m.dlStatus)
 		} else {
-			m.dlStatus = fmt.Sprintf("Successfully downloaded %s", m.dlName)
+			m.dlStatus = fmt.Sprintf("Successfully downloaded %s to %s", m.dlName, m.dlPath)
+			m.addHistory(historySuccess, m.dlStatus)
+		}
+		if len(m.dlQueue) > 0 {
+			next := m.dlQueue[0]
+			m.dlQueue = m.dlQueue[1:]
+			m.dlIndex++
+			return m, m.startDownload(next)
 		}
-		m.addHistory(m.dlStatus)
 		return m, tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
 			return clearStatusMsg{}
 		})
@@ -404,33 +619,132 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.dlStatus = ""
 		return m, nil
 
-	case progress.FrameMsg:
-		progressModel, cmd := m.dlProgress.Update(msg)
-		m.dlProgress = progressModel.(progress.Model)
+	case upProgressMsg:
+		cmd := setPercent(&m.upProgress, float64(msg))
 		return m, cmd
 
+	case upDoneMsg:
+		return m, m.finishUpload(msg)
+
+	case clearUpStatusMsg:
+		m.upStatus = ""
+		return m, nil
+
+	case progress.FrameMsg:
+		// Frame messages carry the bar's ID, so each model ignores the
+		// other's.
+		dlModel, dlCmd := m.dlProgress.Update(msg)
+		m.dlProgress = dlModel.(progress.Model)
+		upModel, upCmd := m.upProgress.Update(msg)
+		m.upProgress = upModel.(progress.Model)
+		return m, tea.Batch(dlCmd, upCmd)
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 	}
 
+	// Let the copy destination input receive cursor blink messages.
+	if m.overlay == overlayCopy {
+		var cmd tea.Cmd
+		m.copyInput, cmd = m.copyInput.Update(msg)
+		return m, cmd
+	}
+	if m.overlay == overlayRename {
+		var cmd tea.Cmd
+		m.renameInput, cmd = m.renameInput.Update(msg)
+		return m, cmd
+	}
+	if m.overlay == overlayUpload {
+		var cmd tea.Cmd
+		m.upInput, cmd = m.upInput.Update(msg)
+		return m, cmd
+	}
+
 	return m, nil
 }
 
+// Layout limits. Below minWidth x minHeight the TUI shows a notice instead
+// of panes; below singleColumnWidth only the active pane is drawn; the
+// bottom panels are dropped when their columns or the panes would get too
+// cramped.
+const (
+	minWidth          = 40
+	minHeight         = 12
+	singleColumnWidth = 72
+	leftPaneWidth     = 30
+	minBottomColWidth = 25
+	minPaneHeight     = 8
+	bottomPanelHeight = 7 // five rows plus the border
+)
+
+// tooSmall reports whether the terminal cannot fit a usable layout. Before
+// the first WindowSizeMsg the size is unknown and assumed to be fine.
+func (m *model) tooSmall() bool {
+	if m.width == 0 && m.height == 0 {
+		return false
+	}
+	return m.width < minWidth || m.height < minHeight
+}
+
+func (m *model) singleColumn() bool {
+	return m.width > 0 && m.width < singleColumnWidth
+}
+
+func (m *model) bottomColWidth() int {
+	return (m.width - 4) / 3
+}
+
+func (m *model) showBottomPanels() bool {
+	return m.bottomColWidth() >= minBottomColWidth && m.height-2-bottomPanelHeight >= minPaneHeight
+}
+
 func (m *model) getViewHeight() int {
-	h := m.height - 9
+	h := m.height - 2
+	if m.showBottomPanels() {
+		h -= bottomPanelHeight
+	}
 	if h < 5 {
 		h = 5
 	}
 	return h
 }
 
+// paneWidths returns the content widths of the bucket and object panes. In
+// single-column mode both get the full width since only one is drawn.
+func (m *model) paneWidths() (left, right int) {
+	if m.singleColumn() {
+		w := max(m.width-4, 20)
+		return w, w
+	}
+	left = leftPaneWidth
+	right = m.width - left - 6
+	if right < 20 {
+		right = 20
+	}
+	return left, right
+}
+
+func (m *model) viewTooSmall() string {
+	msg := fmt.Sprintf("Terminal too small (%dx%d)\nResize to at least %dx%d, or press q to quit",
+		m.width, m.height, minWidth, minHeight)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, msg)
+}
+
 func (m *model) View() string {
 	if m.err != nil {
 		return fmt.Sprintf("\n  Error: %v\n\n  Press q to quit", m.err)
 	}
 
+	if m.tooSmall() {
+		return m.viewTooSmall()
+	}
+
+	if m.client == nil {
+		return m.viewProfilePicker()
+	}
+
 	paneHeight := m.getViewHeight()
 
 	bucketList := []string{headerStyle.Render("BUCKETS")}
@@ -458,6 +772,9 @@ func (m *model) View() string {
 
 	var objectList []string
 	prefixTitle := m.bucket + "/" + m.prefix
+	if m.bucketRegion != "" {
+		prefixTitle += " [" + m.bucketRegion + "]"
+	}
 	if m.loading {
 		prefixTitle += " " + m.spinner.View()
 	}
@@ -469,20 +786,10 @@ func (m *model) View() string {
 		endO = len(m.objects)
 	}
 
-	for i := startO; i < endO; i++ {
-		o := m.objects[i]
-		var icon string
-		if o.IsDir {
-			icon = dirStyle.Render("[DIR]")
-		} else {
-			icon = fileStyle.Render("[FILE]")
-		}
-
-		label := icon + " " + o.Name
-		if !o.IsDir {
-			label += fmt.Sprintf("  %s", formatSize(o.Size))
-		}
+	leftWidth, rightWidth := m.paneWidths()
 
+	for i := startO; i < endO; i++ {
+		label := m.objectRow(m.objects[i], rightWidth-6)
 		s := itemStyle.Render(label)
 		if i == m.cursorObject && m.activePane == paneObjects {
 			s = selectedItemStyle.Render("> " + label)
@@ -498,12 +805,6 @@ func (m *model) View() string {
 		objectsView = lipgloss.JoinVertical(lipgloss.Left, headerStyle.Render(prefixTitle), emptyMsg)
 	}
 
-	leftWidth := 30
-	rightWidth := m.width - leftWidth - 6
-	if rightWidth < 20 {
-		rightWidth = 20
-	}
-
 	leftStyle := paneStyle.Width(leftWidth).Height(paneHeight).MaxHeight(paneHeight)
 	if m.activePane == paneBuckets {
 		leftStyle = activePaneStyle.Width(leftWidth).Height(paneHeight).MaxHeight(paneHeight)
@@ -514,24 +815,91 @@ func (m *model) View() string {
 		rightStyle = activePaneStyle.Width(rightWidth).Height(paneHeight).MaxHeight(paneHeight)
 	}
 
-	panes := lipgloss.JoinHorizontal(lipgloss.Top,
-		leftStyle.Render(bucketsView),
-		rightStyle.Render(objectsView),
-	)
+	var panes string
+	switch {
+	case !m.singleColumn():
+		panes = lipgloss.JoinHorizontal(lipgloss.Top,
+			leftStyle.Render(bucketsView),
+			rightStyle.Render(objectsView),
+		)
+	case m.activePane == paneBuckets:
+		panes = leftStyle.Render(bucketsView)
+	default:
+		panes = rightStyle.Render(objectsView)
+	}
 
-	var bottomView string
+	helpView := helpStyle.Render(m.help.View(m.keys))
+
+	finalView := lipgloss.JoinVertical(lipgloss.Left, panes, helpView)
+	if m.showBottomPanels() {
+		finalView = lipgloss.JoinVertical(lipgloss.Left, panes, m.viewBottomPanels(), helpView)
+	}
+
+	if m.overlay == overlayPalette {
+		return m.placeOverlay(finalView, m.viewPalette())
+	}
+
+	if m.overlay == overlayCopy {
+		return m.placeOverlay(finalView, m.viewCopyInput())
+	}
+
+	if m.overlay == overlayPreview {
+		return m.placeOverlay(finalView, m.viewPreview())
+	}
+
+	if m.overlay == overlayRename {
+		return m.placeOverlay(finalView, m.viewRenameInput())
+	}
+
+	if m.overlay == overlayUpload {
+		return m.placeOverlay(finalView, m.viewUploadInput())
+	}
 
-	colWidth := (m.width - 4) / 3
-	if colWidth < 25 {
-		colWidth = 25
+	if m.overlay == overlayProperties && m.propEntry != nil {
+		props := dialogStyle.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				headerStyle.Render("PROPERTIES: "+m.propEntry.Name),
+				"",
+				fmt.Sprintf("Size:          %s", ui.FormatSize(m.propEntry.Size)),
+				fmt.Sprintf("Last Modified: %s", formatTime(m.propEntry.LastModified)),
+				fmt.Sprintf("Storage Class: %s", m.propEntry.StorageClass),
+				fmt.Sprintf("ETag:          %s", m.propEntry.ETag),
+				"",
+				lipgloss.NewStyle().Foreground(subtleColor).Render("Press Esc to close"),
+			),
+		)
+		return m.placeOverlay(finalView, props)
 	}
 
+	return finalView
+}
+
+// viewBottomPanels renders the progress, history and metadata columns.
+func (m *model) viewBottomPanels() string {
+	colWidth := m.bottomColWidth()
+
 	var progressContent string
 	if m.downloading {
-		progressContent = fmt.Sprintf("Downloading: %s\n%s", m.dlName, m.dlProgress.View())
+		label := "Downloading"
+		if m.dlTotal > 1 {
+			label = fmt.Sprintf("Downloading (%d/%d)", m.dlIndex, m.dlTotal)
+		}
+		progressContent = fmt.Sprintf("%s: %s\n%s", label, m.dlName, m.dlProgress.View())
 	} else if m.dlStatus != "" {
 		progressContent = m.dlStatus
-	} else {
+	}
+	var uploadContent string
+	if m.uploading {
+		uploadContent = fmt.Sprintf("Uploading: %s\n%s", m.upName, m.upProgress.View())
+	} else if m.upStatus != "" {
+		uploadContent = m.upStatus
+	}
+	switch {
+	case progressContent != "" && uploadContent != "":
+		progressContent += "\n" + uploadContent
+	case uploadContent != "":
+		progressContent = uploadContent
+	case progressContent == "":
 		progressContent = "No active transfers"
 	}
 	progressCol := bottomPanelStyle.Width(colWidth).Height(5).MaxHeight(5).Render(
@@ -547,7 +915,11 @@ func (m *model) View() string {
 	}
 	var historyContent string
 	if len(historyToShow) > 0 {
-		historyContent = lipgloss.JoinVertical(lipgloss.Left, historyToShow...)
+		lines := make([]string, len(historyToShow))
+		for i, e := range historyToShow {
+			lines[i] = e.render()
+		}
+		historyContent = lipgloss.JoinVertical(lipgloss.Left, lines...)
 	} else {
 		historyContent = "No history"
 	}
@@ -560,20 +932,34 @@ func (m *model) View() string {
 
 	var metadataContent string
 	if m.activePane == paneBuckets && len(m.buckets) > 0 {
-		metadataContent = fmt.Sprintf("Bucket: %s", m.buckets[m.cursorBucket])
+		b := m.buckets[m.cursorBucket]
+		metadataContent = fmt.Sprintf("Bucket: %s", b)
+		if region, ok := m.regions[b]; ok {
+			metadataContent += fmt.Sprintf("\nRegion: %s", region)
+		}
 	} else if m.activePane == paneObjects && len(m.objects) > 0 {
 		obj := m.objects[m.cursorObject]
+		size := ui.FormatSize(obj.Size)
+		if obj.IsDir {
+			size = "computing…"
+			if d, ok := m.dirSizes[dirSizeKey(m.bucket, m.prefix+obj.Name)]; ok {
+				size = d.String()
+			}
+		}
 		metadataContent = fmt.Sprintf("Name: %s\nSize: %s\nType: %s",
 			obj.Name,
-			formatSize(obj.Size),
+			size,
 			map[bool]string{true: "Directory", false: "File"}[obj.IsDir],
 		)
-		if obj.LastModified != nil {
-			metadataContent += fmt.Sprintf("\nModified: %s", *obj.LastModified)
+		if !obj.LastModified.IsZero() {
+			metadataContent += fmt.Sprintf("\nModified: %s", formatTime(obj.LastModified))
 		}
 	} else {
 		metadataContent = "No selection"
 	}
+	if len(m.selected) > 0 {
+		metadataContent += fmt.Sprintf("\nSelected: %d", len(m.selected))
+	}
 	metadataCol := bottomPanelStyle.Width(colWidth).Height(5).MaxHeight(5).Render(
 		lipgloss.JoinVertical(lipgloss.Left,
 			headerStyle.Width(colWidth-2).Render("METADATA"),
@@ -581,54 +967,11 @@ func (m *model) View() string {
 		),
 	)
 
-	bottomView = lipgloss.JoinHorizontal(lipgloss.Top,
+	return lipgloss.JoinHorizontal(lipgloss.Top,
 		progressCol,
 		historyCol,
 		metadataCol,
 	)
-
-	helpView := helpStyle.Render(m.help.View(m.keys))
-
-	finalView := lipgloss.JoinVertical(lipgloss.Left,
-		panes,
-		bottomView,
-		helpView,
-	)
-
-	if m.overlay == overlayPalette {
-		palette := dialogStyle.Render(
-			lipgloss.JoinVertical(lipgloss.Center,
-				headerStyle.Render("COMMAND PALETTE"),
-				"",
-				"Available Commands:",
-				"• Refresh (r)",
-				"• Upload (u) - Use CLI",
-				"• Delete (d) - Use CLI",
-				"• Copy S3 URI (c)",
-				"",
-				lipgloss.NewStyle().Foreground(subtleColor).Render("Press Esc to close"),
-			),
-		)
-		return m.placeOverlay(finalView, palette)
-	}
-
-	if m.overlay == overlayProperties && m.propEntry != nil {
-		props := dialogStyle.Render(
-			lipgloss.JoinVertical(lipgloss.Left,
-				headerStyle.Render("PROPERTIES: "+m.propEntry.Name),
-				"",
-				fmt.Sprintf("Size:          %s", formatSize(m.propEntry.Size)),
-				fmt.Sprintf("Last Modified: %s", *m.propEntry.LastModified),
-				fmt.Sprintf("Storage Class: %s", m.propEntry.StorageClass),
-				fmt.Sprintf("ETag:          %s", m.propEntry.ETag),
-				"",
-				lipgloss.NewStyle().Foreground(subtleColor).Render("Press Esc to close"),
-			),
-		)
-		return m.placeOverlay(finalView, props)
-	}
-
-	return finalView
 }
 
 func (m *model) placeOverlay(base string, overlay string) string {
@@ -653,40 +996,189 @@ func (m *model) placeOverlay(base string, overlay string) string {
 	)
 }
 
-func formatSize(size int64) string {
-	if size < 1024 {
-		return fmt.Sprintf("%d B", size)
+const (
+	iconColWidth     = 7
+	sizeColWidth     = 10
+	modifiedColWidth = 19
+	classColWidth    = 12
+	colGap           = 2
+)
+
+// objectRow lays out one entry of the object pane as aligned name, size,
+// modified and storage class columns within width cells. The modified and
+// class columns are dropped when the pane is too narrow for them.
+func (m *model) objectRow(o S3Entry, width int) string {
+	icon := fileStyle.Render("[FILE]") + " "
+	size, modified := ui.FormatSize(o.Size), ""
+	if !o.LastModified.IsZero() {
+		modified = formatTime(o.LastModified)
+	}
+	if o.IsDir {
+		icon = dirStyle.Render("[DIR]") + "  "
+		size, modified = "—", "—"
+	}
+
+	name := o.Name
+	if m.selected[m.prefix+o.Name] {
+		name = "✓ " + name
+	}
+
+	nameWidth := width - iconColWidth - (sizeColWidth + colGap) - (modifiedColWidth + colGap) - (classColWidth + colGap)
+	detailed := nameWidth >= 12
+	if !detailed {
+		nameWidth = width - iconColWidth - (sizeColWidth + colGap)
+	}
+	if nameWidth < 8 {
+		nameWidth = 8
+	}
+
+	gap := strings.Repeat(" ", colGap)
+	row := icon + padRight(truncate(name, nameWidth), nameWidth) + gap + padLeft(size, sizeColWidth)
+	if detailed {
+		row += gap + padRight(truncate(modified, modifiedColWidth), modifiedColWidth) +
+			gap + truncate(o.StorageClass, classColWidth)
+	}
+	return row
+}
+
+// truncate shortens s to at most width cells, marking the cut with "…".
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func padRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
 	}
-	if size < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	return s
+}
+
+func padLeft(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return strings.Repeat(" ", width-w) + s
 	}
-	if size < 1024*1024*1024 {
-		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	return s
+}
+
+// historyKind decides how a history entry is coloured.
+type historyKind int
+
+const (
+	historyInfo historyKind = iota
+	historySuccess
+	historyError
+)
+
+type historyEntry struct {
+	kind historyKind
+	text string
+}
+
+func (e historyEntry) render() string {
+	switch e.kind {
+	case historySuccess:
+		return ui.StatusSuccessStyle.Render(e.text)
+	case historyError:
+		return ui.StatusErrorStyle.Render(e.text)
 	}
-	return fmt.Sprintf("%.1f GB", float64(size)/(1024*1024*1024))
+	return historyInfoStyle.Render(e.text)
 }
 
-func (m *model) addHistory(msg string) {
-	m.taskHistory = append(m.taskHistory, fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), msg))
+// formatTime renders a modification time in local time for the panes and
+// dialogs.
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func (m *model) addHistory(kind historyKind, msg string) {
+	m.taskHistory = append(m.taskHistory, historyEntry{
+		kind: kind,
+		text: fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), msg),
+	})
 	if len(m.taskHistory) > 100 {
 		m.taskHistory = m.taskHistory[1:]
 	}
 }
 
-func (m *model) startDownload(obj S3Entry) tea.Cmd {
-	key := m.prefix + obj.Name
-	m.dlName = obj.Name
+// startBatchDownload queues every selected key and downloads them one after
+// another, clearing the selection.
+func (m *model) startBatchDownload() tea.Cmd {
+	if m.downloading {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m.selected))
+	for k := range m.selected {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	m.selected = nil
+
+	m.dlBucket = m.bucket
+	m.dlClient = m.objectClient()
+	m.dlQueue = keys[1:]
+	m.dlIndex, m.dlTotal = 1, len(keys)
+	m.addHistory(historyInfo, fmt.Sprintf("Batch download started: %d objects", len(keys)))
+	return m.startDownload(keys[0])
+}
+
+// setPercent sets bar's target on a copy. The animation tick returned by
+// progress.Model.SetPercent keeps a pointer to the bar it was called on and
+// reads it later from another goroutine, so it must not point into the
+// model that Update keeps mutating.
+func setPercent(bar *progress.Model, p float64) tea.Cmd {
+	b := *bar
+	cmd := b.SetPercent(p)
+	*bar = b
+	return cmd
+}
+
+func (m *model) startDownload(key string) tea.Cmd {
+	bucket, client, concurrency := m.dlBucket, m.dlClient, m.dlConcurrency
+	outputPath := uniquePath(filepath.Join(m.downloadDir, path.Base(key)))
+	m.dlName = path.Base(key)
+	m.dlPath = outputPath
 	m.downloading = true
-	m.dlProgress.SetPercent(0)
+	setPercent(&m.dlProgress, 0)
 	m.dlStatus = ""
 
+	return downloadCmd(m.program, client, bucket, key, outputPath, concurrency)
+}
+
+// downloadCmd runs a download off the Update loop. It closes over its
+// arguments only, never the model, which Update may be changing meanwhile;
+// progress and the result come back as messages.
+func downloadCmd(program *tea.Program, client *s3.Client, bucket, key, outputPath string, concurrency int) tea.Cmd {
 	return func() tea.Msg {
-		outputPath := filepath.Base(obj.Name)
-		err := downloadObject(context.Background(), m.client, m.bucket, key, outputPath, func(p Progress) {
-			if m.program != nil {
-				m.program.Send(dlProgressMsg(float64(p.DownloadedBytes) / float64(p.TotalBytes)))
+		err := downloadObject(context.Background(), client, bucket, key, outputPath, concurrency, func(p Progress) {
+			if program != nil && p.TotalBytes > 0 {
+				program.Send(dlProgressMsg(float64(p.DownloadedBytes) / float64(p.TotalBytes)))
 			}
 		})
 		return dlDoneMsg{err: err}
 	}
 }
+
+// uniquePath returns p, or p with " (1)", " (2)", ... inserted before the
+// extension if a file already exists there, so downloads of equally named
+// keys from different prefixes never overwrite each other.
+func uniquePath(p string) string {
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return p
+	}
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type paletteAction int
+
+const (
+	actionRefresh paletteAction = iota
+	actionCopyTo
+	actionRename
+	actionProperties
+)
+
+var paletteItems = []struct {
+	action paletteAction
+	label  string
+}{
+	{actionRefresh, "Refresh (r)"},
+	{actionCopyTo, "Copy to…"},
+	{actionRename, "Rename… (R)"},
+	{actionProperties, "Properties"},
+}
+
+type copyDoneMsg struct {
+	src      string
+	dst      string
+	warnings []string
+	err      error
+}
+
+type renameDoneMsg struct {
+	src      string
+	dst      string
+	warnings []string
+	err      error
+}
+
+// selectedObject returns the file under the cursor in the object pane.
+func (m *model) selectedObject() (S3Entry, bool) {
+	if m.bucket == "" || len(m.objects) == 0 {
+		return S3Entry{}, false
+	}
+	obj := m.objects[m.cursorObject]
+	if obj.IsDir {
+		return S3Entry{}, false
+	}
+	return obj, true
+}
+
+func (m *model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.overlay = overlayNone
+	case "up", "k":
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+	case "down", "j":
+		if m.paletteCursor < len(paletteItems)-1 {
+			m.paletteCursor++
+		}
+	case "enter":
+		m.overlay = overlayNone
+		return m, m.runPaletteAction(paletteItems[m.paletteCursor].action)
+	}
+	return m, nil
+}
+
+func (m *model) runPaletteAction(action paletteAction) tea.Cmd {
+	switch action {
+	case actionRefresh:
+		m.loading = true
+		if m.bucket == "" {
+			return m.loadBuckets
+		}
+		return m.loadObjects
+
+	case actionCopyTo:
+		obj, ok := m.selectedObject()
+		if !ok {
+			m.addHistory(historyInfo, "Copy: select a file first")
+			return nil
+		}
+		m.copySource = m.prefix + obj.Name
+		m.copyInput = textinput.New()
+		m.copyInput.Placeholder = "s3://bucket/prefix/"
+		m.copyInput.SetValue("s3://" + m.bucket + "/" + m.prefix)
+		m.copyInput.CursorEnd()
+		m.copyInput.Width = 50
+		m.overlay = overlayCopy
+		return m.copyInput.Focus()
+
+	case actionRename:
+		return m.startRename()
+
+	case actionProperties:
+		obj, ok := m.selectedObject()
+		if !ok {
+			m.addHistory(historyInfo, "Properties: select a file first")
+			return nil
+		}
+		m.loading = true
+		return m.loadMetadata(m.bucket, m.prefix+obj.Name)
+	}
+	return nil
+}
+
+func (m *model) updateCopyInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.overlay = overlayNone
+		return m, nil
+	case "enter":
+		dstBucket, dstPrefix, err := s3uri.ParsePrefix(strings.TrimSpace(m.copyInput.Value()))
+		if err != nil {
+			m.addHistory(historyError, fmt.Sprintf("Copy failed: %v", err))
+			m.overlay = overlayNone
+			return m, nil
+		}
+		dstKey := dstPrefix
+		if dstKey == "" || strings.HasSuffix(dstKey, "/") {
+			dstKey += path.Base(m.copySource)
+		}
+		m.overlay = overlayNone
+		return m, m.copyObject(m.bucket, m.copySource, dstBucket, dstKey)
+	}
+
+	var cmd tea.Cmd
+	m.copyInput, cmd = m.copyInput.Update(msg)
+	return m, cmd
+}
+
+// startRename opens the rename dialog prefilled with the current name.
+func (m *model) startRename() tea.Cmd {
+	obj, ok := m.selectedObject()
+	if !ok {
+		m.addHistory(historyInfo, "Rename: select a file first")
+		return nil
+	}
+	m.renameSource = m.prefix + obj.Name
+	m.renameInput = textinput.New()
+	m.renameInput.SetValue(obj.Name)
+	m.renameInput.CursorEnd()
+	m.renameInput.Width = 50
+	m.overlay = overlayRename
+	return m.renameInput.Focus()
+}
+
+func (m *model) updateRenameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.overlay = overlayNone
+		return m, nil
+	case "enter":
+		m.overlay = overlayNone
+		name := strings.TrimSpace(m.renameInput.Value())
+		if name == "" || strings.HasSuffix(name, "/") {
+			m.addHistory(historyInfo, "Rename: enter a file name")
+			return m, nil
+		}
+		// Names are relative to the current prefix; a leading / makes them
+		// absolute within the bucket.
+		dstKey := m.prefix + name
+		if strings.HasPrefix(name, "/") {
+			dstKey = strings.TrimPrefix(name, "/")
+		}
+		if dstKey == m.renameSource {
+			return m, nil
+		}
+		return m, m.renameObject(m.bucket, m.renameSource, dstKey)
+	}
+
+	var cmd tea.Cmd
+	m.renameInput, cmd = m.renameInput.Update(msg)
+	return m, cmd
+}
+
+// preservingCopy carries the source's ACL and tags over, as cp does by
+// default, and collects the attributes it could not read into warnings.
+func preservingCopy(warnings *[]string) s3ops.CopyOptions {
+	return s3ops.CopyOptions{
+		PreserveACL:  true,
+		PreserveTags: true,
+		Warn:         func(err error) { *warnings = append(*warnings, err.Error()) },
+	}
+}
+
+func (m *model) renameObject(bucket, oldKey, newKey string) tea.Cmd {
+	client := m.objectClient()
+	return func() tea.Msg {
+		var warnings []string
+		err := s3ops.RenameObject(context.Background(), client, bucket, oldKey, newKey, preservingCopy(&warnings))
+		return renameDoneMsg{src: oldKey, dst: newKey, warnings: warnings, err: err}
+	}
+}
+
+func (m *model) copyObject(srcBucket, srcKey, dstBucket, dstKey string) tea.Cmd {
+	client := m.objectClient()
+	return func() tea.Msg {
+		var warnings []string
+		err := s3ops.CopyObjectWithOptions(context.Background(), client, srcBucket, srcKey, dstBucket, dstKey, preservingCopy(&warnings))
+		return copyDoneMsg{
+			src:      fmt.Sprintf("s3://%s/%s", srcBucket, srcKey),
+			dst:      fmt.Sprintf("s3://%s/%s", dstBucket, dstKey),
+			warnings: warnings,
+			err:      err,
+		}
+	}
+}
+
+func (m *model) viewPalette() string {
+	lines := []string{
+		headerStyle.Render("COMMAND PALETTE"),
+		"",
+	}
+	for i, item := range paletteItems {
+		s := itemStyle.Render(item.label)
+		if i == m.paletteCursor {
+			s = selectedItemStyle.Render("> " + item.label)
+		}
+		lines = append(lines, s)
+	}
+	lines = append(lines,
+		"",
+		lipgloss.NewStyle().Foreground(subtleColor).Render("Enter to run, Esc to close"),
+	)
+	return dialogStyle.Align(lipgloss.Left).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m *model) viewCopyInput() string {
+	return dialogStyle.Align(lipgloss.Left).Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			headerStyle.Render("COPY TO"),
+			"",
+			fmt.Sprintf("Source: s3://%s/%s", m.bucket, m.copySource),
+			"",
+			m.copyInput.View(),
+			"",
+			lipgloss.NewStyle().Foreground(subtleColor).Render("Enter to copy, Esc to cancel"),
+		),
+	)
+}
+
+func (m *model) viewRenameInput() string {
+	return dialogStyle.Align(lipgloss.Left).Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			headerStyle.Render("RENAME"),
+			"",
+			fmt.Sprintf("Object: s3://%s/%s", m.bucket, m.renameSource),
+			"",
+			m.renameInput.View(),
+			"",
+			lipgloss.NewStyle().Foreground(subtleColor).Render("Enter to rename, Esc to cancel"),
+		),
+	)
+}
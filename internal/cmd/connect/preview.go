@@ -0,0 +1,163 @@
+package connect
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// previewTextBytes is how much of a text object the preview fetches.
+	previewTextBytes = 64 * 1024
+	// previewHexBytes is how much of a binary object is shown as a hexdump.
+	previewHexBytes = 512
+)
+
+type previewMsg struct {
+	name    string
+	content string
+	err     error
+}
+
+// isTextContentType reports whether objects of contentType are likely
+// readable as text.
+func isTextContentType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if strings.HasPrefix(ct, "text/") {
+		return true
+	}
+	switch ct {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-yaml", "application/yaml", "application/x-sh",
+		"application/toml", "application/x-ndjson", "application/csv":
+		return true
+	}
+	return strings.HasSuffix(ct, "+json") || strings.HasSuffix(ct, "+xml")
+}
+
+// looksLikeText is the fallback for objects stored without a useful
+// Content-Type: valid UTF-8 with no NUL bytes.
+func looksLikeText(data []byte) bool {
+	if !utf8.Valid(data) {
+		// The range may have cut a multi-byte rune in half.
+		cut := len(data) - utf8.UTFMax
+		if cut < 0 || !utf8.Valid(data[:cut]) {
+			return false
+		}
+	}
+	return !strings.ContainsRune(string(data), 0)
+}
+
+// loadPreview fetches the start of key: up to previewTextBytes for text and
+// previewHexBytes, rendered as a hexdump, for anything else.
+func (m *model) loadPreview(bucket, key, name string) tea.Cmd {
+	client := m.objectClient()
+	return func() tea.Msg {
+		ctx := context.Background()
+		meta, err := s3ops.HeadObject(ctx, client, bucket, key)
+		if err != nil {
+			return previewMsg{name: name, err: err}
+		}
+		if meta.Size == 0 {
+			return previewMsg{name: name, content: "(empty object)"}
+		}
+
+		ct := meta.ContentType
+		known := ct != "" && ct != "application/octet-stream" && ct != "binary/octet-stream"
+		text := isTextContentType(ct)
+
+		n := int64(previewHexBytes)
+		if text || !known {
+			n = previewTextBytes
+		}
+		if n > meta.Size {
+			n = meta.Size
+		}
+
+		data, err := s3ops.DownloadRange(ctx, client, bucket, key, s3ops.RangeDownload{Start: 0, End: n - 1})
+		if err != nil {
+			return previewMsg{name: name, err: err}
+		}
+
+		if text || (!known && looksLikeText(data)) {
+			content := strings.ReplaceAll(string(data), "\r\n", "\n")
+			content = strings.ReplaceAll(content, "\t", "    ")
+			if meta.Size > n {
+				content += fmt.Sprintf("\n… (showing first %s of %s)", ui.FormatSize(n), ui.FormatSize(meta.Size))
+			}
+			return previewMsg{name: name, content: content}
+		}
+
+		if len(data) > previewHexBytes {
+			data = data[:previewHexBytes]
+		}
+		content := fmt.Sprintf("Binary content (%s), first %d bytes:\n\n%s", ct, len(data), hex.Dump(data))
+		return previewMsg{name: name, content: content}
+	}
+}
+
+func (m *model) openPreview(msg previewMsg) {
+	m.loading = false
+	if msg.err != nil {
+		m.addHistory(historyError, fmt.Sprintf("Preview failed: %s: %v", msg.name, msg.err))
+		return
+	}
+
+	m.preview = viewport.New(m.previewSize())
+	m.preview.SetContent(msg.content)
+	m.previewName = msg.name
+	m.overlay = overlayPreview
+}
+
+// previewSize fits the preview viewport inside the terminal, leaving room
+// for the dialog border, padding, title and footer.
+func (m *model) previewSize() (w, h int) {
+	w, h = m.width-12, m.height-12
+	if w < 20 {
+		w = 20
+	}
+	if h < 3 {
+		h = 3
+	}
+	return w, h
+}
+
+func (m *model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "p":
+		m.overlay = overlayNone
+		return m, nil
+	case "home":
+		m.preview.GotoTop()
+		return m, nil
+	case "end":
+		m.preview.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.preview, cmd = m.preview.Update(msg)
+	return m, cmd
+}
+
+func (m *model) viewPreview() string {
+	footer := fmt.Sprintf("%3.0f%%  ↑/↓ scroll, Esc to close", m.preview.ScrollPercent()*100)
+	return dialogStyle.Align(lipgloss.Left).Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			headerStyle.Render("PREVIEW: "+m.previewName),
+			"",
+			m.preview.View(),
+			"",
+			lipgloss.NewStyle().Foreground(subtleColor).Render(footer),
+		),
+	)
+}
@@ -0,0 +1,86 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+
+	"s3-client/internal/shared/config"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type clientMsg struct {
+	client  *s3.Client
+	profile string
+}
+
+type profileErrMsg struct{ err error }
+
+// connectProfile loads the named profile and checks that it yields
+// credentials before handing the client to the browser.
+func (m *model) connectProfile(profile string) tea.Cmd {
+	opts := m.opts
+	opts.Profile = profile
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		cfg, err := config.Load(ctx, opts)
+		if err != nil {
+			return profileErrMsg{err}
+		}
+		if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+			return profileErrMsg{err}
+		}
+		return clientMsg{client: s3.NewFromConfig(cfg), profile: profile}
+	}
+}
+
+func (m *model) updateProfilePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursorProfile > 0 {
+			m.cursorProfile--
+		}
+	case "down", "j":
+		if m.cursorProfile < len(m.profiles)-1 {
+			m.cursorProfile++
+		}
+	case "enter":
+		if len(m.profiles) > 0 && !m.loading {
+			m.loading = true
+			m.profileErr = nil
+			return m, m.connectProfile(m.profiles[m.cursorProfile])
+		}
+	}
+	return m, nil
+}
+
+func (m *model) viewProfilePicker() string {
+	lines := []string{
+		headerStyle.Render("SELECT AWS PROFILE"),
+		"",
+		"No usable credentials were found. Pick a profile:",
+		"",
+	}
+	for i, name := range m.profiles {
+		s := itemStyle.Render(name)
+		if i == m.cursorProfile {
+			s = selectedItemStyle.Render("> " + name)
+		}
+		lines = append(lines, s)
+	}
+	lines = append(lines, "")
+	if m.loading {
+		lines = append(lines, m.spinner.View()+" Connecting...")
+	} else if m.profileErr != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(highlightColor).Render(fmt.Sprintf("Error: %v", m.profileErr)))
+	}
+	lines = append(lines, lipgloss.NewStyle().Foreground(subtleColor).Render("Enter to connect, Esc to quit"))
+
+	picker := dialogStyle.Align(lipgloss.Left).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	return m.placeOverlay("", picker)
+}
@@ -0,0 +1,58 @@
+package connect
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type bucketRegionMsg struct {
+	bucket string
+	region string
+	client *s3.Client
+	err    error
+}
+
+// objectClient returns the client for the open bucket, which may target a
+// different region than the client used to list buckets.
+func (m *model) objectClient() *s3.Client {
+	if m.bucketClient != nil {
+		return m.bucketClient
+	}
+	return m.client
+}
+
+// resolveBucket looks up the bucket's region (once per bucket) and returns a
+// client for it. Custom endpoints are used as configured.
+func (m *model) resolveBucket(bucket string) tea.Cmd {
+	base, factory, opts := m.client, m.factory, m.opts
+	cached, ok := m.regions[bucket]
+
+	return func() tea.Msg {
+		if opts.Endpoint != "" {
+			return bucketRegionMsg{bucket: bucket, client: base}
+		}
+
+		ctx := context.Background()
+		region := cached
+		if !ok {
+			var err error
+			region, err = factory.BucketRegion(ctx, opts, bucket)
+			if err != nil {
+				return bucketRegionMsg{bucket: bucket, client: base, err: err}
+			}
+		}
+
+		if region == base.Options().Region {
+			return bucketRegionMsg{bucket: bucket, region: region, client: base}
+		}
+
+		opts.Region = region
+		client, err := factory.GetClient(ctx, opts)
+		if err != nil {
+			return bucketRegionMsg{bucket: bucket, region: region, client: base, err: err}
+		}
+		return bucketRegionMsg{bucket: bucket, region: region, client: client}
+	}
+}
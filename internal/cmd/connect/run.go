@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/smithy-go/logging"
@@ -30,6 +31,8 @@ func printUsage(fs *flag.FlagSet) {
 
 func Run(args []string) int {
 	fs := newFlagSet()
+	downloadDir := fs.String("download-dir", ".", "Directory where downloaded objects are saved")
+	concurrency := fs.Int("concurrency", s3ops.DefaultConcurrency, "Number of parallel chunk requests per download")
 
 	opts := &config.Options{}
 	config.AddFlags(fs, opts)
@@ -42,17 +45,39 @@ func Run(args []string) int {
 		return 1
 	}
 
-	awsCfg, err := config.Load(context.Background(), *opts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+	if *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -concurrency must be at least 1")
 		return 1
 	}
 
+	if info, err := os.Stat(*downloadDir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "Error: -download-dir %q is not a directory\n", *downloadDir)
+		return 1
+	}
+
+	ctx := context.Background()
+	awsCfg, err := config.Load(ctx, *opts)
+	if err == nil {
+		_, err = awsCfg.Credentials.Retrieve(ctx)
+	}
+
 	_ = noopLogger{}
 
-	client := s3.NewFromConfig(awsCfg)
+	// Without usable credentials, start on the profile picker rather than
+	// failing outright.
+	var client *s3.Client
+	var profiles []string
+	if err == nil {
+		client = s3.NewFromConfig(awsCfg)
+	} else {
+		profiles, _ = config.ListProfiles()
+		if len(profiles) == 0 {
+			fmt.Fprintf(os.Stderr, "Failed to load AWS credentials: %v\n", err)
+			return 1
+		}
+	}
 
-	m := initialModel(client)
+	m := initialModel(client, *opts, profiles, *downloadDir, *concurrency)
 	p := tea.NewProgram(&m, tea.WithAltScreen())
 	m.program = p
 
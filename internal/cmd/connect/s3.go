@@ -3,10 +3,11 @@ package connect
 import (
 	"context"
 	"fmt"
-	"io"
-	"os"
 	"sort"
 	"strings"
+	"time"
+
+	"s3-client/internal/shared/s3ops"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -16,7 +17,7 @@ type S3Entry struct {
 	Name         string
 	IsDir        bool
 	Size         int64
-	LastModified *string
+	LastModified time.Time // zero for directories
 	StorageClass string
 	ETag         string
 }
@@ -84,16 +85,11 @@ func listObjects(ctx context.Context, client *s3.Client, bucket, prefix string)
 				continue
 			}
 
-			lastMod := ""
-			if obj.LastModified != nil {
-				lastMod = obj.LastModified.Format("2006-01-02 15:04:05")
-			}
-
 			entries = append(entries, S3Entry{
 				Name:         name,
 				IsDir:        false,
 				Size:         aws.ToInt64(obj.Size),
-				LastModified: &lastMod,
+				LastModified: aws.ToTime(obj.LastModified),
 				StorageClass: string(obj.StorageClass),
 				ETag:         aws.ToString(obj.ETag),
 			})
@@ -119,60 +115,29 @@ func getObjectMetadata(ctx context.Context, client *s3.Client, bucket, key strin
 		return nil, fmt.Errorf("failed to head object: %w", err)
 	}
 
-	lastMod := ""
-	if resp.LastModified != nil {
-		lastMod = resp.LastModified.Format("2006-01-02 15:04:05")
-	}
-
 	return &S3Entry{
 		Name:         key,
 		IsDir:        false,
 		Size:         aws.ToInt64(resp.ContentLength),
-		LastModified: &lastMod,
+		LastModified: aws.ToTime(resp.LastModified),
 		StorageClass: string(resp.StorageClass),
 		ETag:         aws.ToString(resp.ETag),
 	}, nil
 }
 
-func downloadObject(ctx context.Context, client *s3.Client, bucket, key, outputPath string, progress func(Progress)) error {
-	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	total := aws.ToInt64(resp.ContentLength)
-	downloaded := int64(0)
-	buf := make([]byte, 32*1024)
-
-	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			if _, werr := f.Write(buf[:n]); werr != nil {
-				return werr
-			}
-			downloaded += int64(n)
+// downloadObject fetches key in parallel chunks, using concurrency workers
+// (the shared default when zero).
+func downloadObject(ctx context.Context, client *s3.Client, bucket, key, outputPath string, concurrency int, progress func(Progress)) error {
+	d := &s3ops.ChunkedDownloader{
+		Client:      client,
+		Concurrency: concurrency,
+		Progress: func(p s3ops.DownloadProgress) {
 			progress(Progress{
-				TotalBytes:      total,
-				DownloadedBytes: downloaded,
+				TotalBytes:      p.TotalBytes,
+				DownloadedBytes: p.DownloadedBytes,
 			})
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
+		},
 	}
-
-	return nil
+	_, err := d.DownloadFile(ctx, bucket, key, outputPath)
+	return err
 }
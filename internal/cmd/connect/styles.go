@@ -47,6 +47,8 @@ var (
 
 	spinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("142"))
 
+	historyInfoStyle = lipgloss.NewStyle().Foreground(subtleColor)
+
 	bottomPanelStyle = lipgloss.NewStyle().
 				Border(lipgloss.ThickBorder(), true, true, true, true).
 				BorderForeground(lipgloss.Color("240")).
@@ -0,0 +1,152 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// uploadPartSize is the multipart part size for TUI uploads; smaller files
+// go up in a single PUT.
+const uploadPartSize = 8 * 1024 * 1024
+
+type upProgressMsg float64
+type upDoneMsg struct {
+	bucket, key string
+	err         error
+}
+type clearUpStatusMsg struct{}
+
+// startUploadInput opens the dialog asking for a local file to upload into
+// the current prefix.
+func (m *model) startUploadInput() tea.Cmd {
+	if m.bucket == "" {
+		m.addHistory(historyInfo, "Upload: open a bucket first")
+		return nil
+	}
+	if m.uploading {
+		m.addHistory(historyInfo, "Upload: wait for the current upload to finish")
+		return nil
+	}
+	m.upInput = textinput.New()
+	m.upInput.Placeholder = "/path/to/file"
+	if wd, err := os.Getwd(); err == nil {
+		m.upInput.SetValue(wd + string(filepath.Separator))
+	}
+	m.upInput.CursorEnd()
+	m.upInput.Width = 50
+	m.overlay = overlayUpload
+	return m.upInput.Focus()
+}
+
+func (m *model) updateUploadInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.overlay = overlayNone
+		return m, nil
+	case "enter":
+		m.overlay = overlayNone
+		localPath := strings.TrimSpace(m.upInput.Value())
+		if strings.HasPrefix(localPath, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				localPath = filepath.Join(home, localPath[2:])
+			}
+		}
+		info, err := os.Stat(localPath)
+		if err != nil {
+			m.addHistory(historyError, fmt.Sprintf("Upload failed: %v", err))
+			return m, nil
+		}
+		if !info.Mode().IsRegular() {
+			m.addHistory(historyInfo, "Upload: only single files can be uploaded here; use 's3-client upload' for directories")
+			return m, nil
+		}
+		return m, m.startUpload(localPath, info.Size())
+	}
+
+	var cmd tea.Cmd
+	m.upInput, cmd = m.upInput.Update(msg)
+	return m, cmd
+}
+
+// startUpload uploads localPath into the current prefix, reporting progress
+// through upProgressMsg the same way downloads do.
+func (m *model) startUpload(localPath string, size int64) tea.Cmd {
+	bucket, client := m.bucket, m.objectClient()
+	key := m.prefix + filepath.Base(localPath)
+	m.upName = filepath.Base(localPath)
+	m.uploading = true
+	m.upError = nil
+	m.upStatus = ""
+	setPercent(&m.upProgress, 0)
+	m.addHistory(historyInfo, fmt.Sprintf("Upload started: %s → s3://%s/%s", m.upName, bucket, key))
+
+	return uploadCmd(m.program, client, localPath, bucket, key, size)
+}
+
+// uploadCmd is the upload counterpart of downloadCmd: it never touches the
+// model from its goroutine.
+func uploadCmd(program *tea.Program, client *s3.Client, localPath, bucket, key string, size int64) tea.Cmd {
+	return func() tea.Msg {
+		progress := func(p s3ops.UploadProgress) {
+			if program != nil && p.TotalBytes > 0 {
+				program.Send(upProgressMsg(float64(p.UploadedBytes) / float64(p.TotalBytes)))
+			}
+		}
+		var err error
+		if size < uploadPartSize {
+			err = s3ops.UploadFile(context.Background(), client, localPath, bucket, key, progress)
+		} else {
+			err = s3ops.UploadMultipart(context.Background(), client, localPath, bucket, key, uploadPartSize, progress)
+		}
+		return upDoneMsg{bucket: bucket, key: key, err: err}
+	}
+}
+
+// finishUpload records the result and, if the upload landed in the prefix
+// being viewed, reloads it so the new object shows up.
+func (m *model) finishUpload(msg upDoneMsg) tea.Cmd {
+	m.uploading = false
+	m.upError = msg.err
+	if msg.err != nil {
+		m.upStatus = fmt.Sprintf("Error uploading %s: %v", m.upName, msg.err)
+		m.addHistory(historyError, m.upStatus)
+	} else {
+		m.upStatus = fmt.Sprintf("Uploaded %s to s3://%s/%s", m.upName, msg.bucket, msg.key)
+		m.addHistory(historySuccess, m.upStatus)
+	}
+
+	clear := tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
+		return clearUpStatusMsg{}
+	})
+	if msg.err == nil && msg.bucket == m.bucket && strings.TrimSuffix(msg.key, path.Base(msg.key)) == m.prefix {
+		m.loading = true
+		return tea.Batch(clear, m.loadObjects)
+	}
+	return clear
+}
+
+func (m *model) viewUploadInput() string {
+	return dialogStyle.Align(lipgloss.Left).Render(
+		lipgloss.JoinVertical(lipgloss.Left,
+			headerStyle.Render("UPLOAD"),
+			"",
+			fmt.Sprintf("Destination: s3://%s/%s", m.bucket, m.prefix),
+			"",
+			m.upInput.View(),
+			"",
+			lipgloss.NewStyle().Foreground(subtleColor).Render("Enter to upload, Esc to cancel"),
+		),
+	)
+}
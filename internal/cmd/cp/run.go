@@ -0,0 +1,185 @@
+package cp
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("cp", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client cp [flags] s3://bucket/key s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Copy an object server-side. A destination ending in / keeps the source file name.")
+	fmt.Fprintln(os.Stderr, "-metadata, -content-type and -storage-class replace the source's values; anything")
+	fmt.Fprintln(os.Stderr, "not given is carried over from the source.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client cp s3://src-bucket/file.txt s3://dst-bucket/backup/")
+	fmt.Fprintln(os.Stderr, "  # Change an object's storage class by copying it onto itself")
+	fmt.Fprintln(os.Stderr, "  s3-client cp -storage-class GLACIER_IR s3://my-bucket/old.log s3://my-bucket/old.log")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	metadata := fs.String("metadata", "", "Replace user metadata with key=value pairs (comma-separated)")
+	contentType := fs.String("content-type", "", "Replace the Content-Type")
+	storageClass := fs.String("storage-class", "", "Storage class of the copy (e.g. STANDARD_IA, GLACIER_IR)")
+	resetACL := fs.Bool("reset-acl", false, "Don't carry the source's ACL over; the copy gets the default private ACL")
+	resetTags := fs.Bool("reset-tags", false, "Don't carry the source's tags over")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return 1
+	}
+
+	srcBucket, srcKey, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: source: %v\n", err)
+		return 1
+	}
+	dstBucket, dstKey, err := s3uri.ParsePrefix(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: destination: %v\n", err)
+		return 1
+	}
+	if dstKey == "" || strings.HasSuffix(dstKey, "/") {
+		dstKey += path.Base(srcKey)
+	}
+
+	copyOpts := s3ops.CopyOptions{
+		PreserveACL:  !*resetACL,
+		PreserveTags: !*resetTags,
+		Warn:         func(err error) { fmt.Fprintf(os.Stderr, "Warning: %v\n", err) },
+	}
+	if *storageClass != "" {
+		copyOpts.StorageClass, err = s3ops.ParseStorageClass(*storageClass)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	replace := *metadata != "" || *contentType != "" || *storageClass != ""
+
+	if !replace && srcBucket == dstBucket && srcKey == dstKey {
+		fmt.Fprintln(os.Stderr, "Error: copying an object onto itself needs -metadata, -content-type or -storage-class")
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if replace {
+		// REPLACE drops whatever is not sent, so fill the gaps from the
+		// source to change only what was asked for.
+		src, err := s3ops.HeadObject(ctx, client, srcBucket, srcKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		copyOpts.MetadataDirective = types.MetadataDirectiveReplace
+		copyOpts.Metadata = src.Metadata
+		copyOpts.ContentType = src.ContentType
+		copyOpts.CacheControl = src.CacheControl
+		copyOpts.ContentDisposition = src.ContentDisposition
+		copyOpts.ContentEncoding = src.ContentEncoding
+		copyOpts.ContentLanguage = src.ContentLanguage
+		if *metadata != "" {
+			copyOpts.Metadata = parseMetadata(*metadata)
+		}
+		if *contentType != "" {
+			copyOpts.ContentType = *contentType
+		}
+	}
+
+	out := opts.Out()
+	start := time.Now()
+	var shownProgress bool
+	copyOpts.Progress = func(p s3ops.CopyProgress) {
+		// A single CopyObject reports only once it has finished, which
+		// says nothing a progress bar would add.
+		if p.TotalParts > 1 {
+			printProgress(out, p, time.Since(start))
+			shownProgress = true
+		}
+	}
+
+	err = s3ops.CopyObjectWithOptions(ctx, client, srcBucket, srcKey, dstBucket, dstKey, copyOpts)
+	if shownProgress {
+		fmt.Fprintln(out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Copied s3://%s/%s -> s3://%s/%s in %s\n", srcBucket, srcKey, dstBucket, dstKey, ui.FormatDuration(time.Since(start)))
+	return 0
+}
+
+// printProgress redraws the multipart copy progress line. The data moves
+// inside S3, so the rate is S3's copy throughput, not local bandwidth.
+func printProgress(w io.Writer, p s3ops.CopyProgress, elapsed time.Duration) {
+	pct := 100.0
+	if p.TotalBytes > 0 {
+		pct = float64(p.CopiedBytes) / float64(p.TotalBytes) * 100
+	}
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.CopiedBytes) / elapsed.Seconds()
+	}
+	fmt.Fprintf(w, "\r%s %5.1f%%  %d/%d parts  %s / %s  server-side copy rate %s/s  %s",
+		ui.ProgressBar(30, pct), pct, p.PartsDone, p.TotalParts,
+		ui.FormatSize(p.CopiedBytes), ui.FormatSize(p.TotalBytes), ui.FormatSize(int64(rate)), ui.FormatDuration(elapsed))
+}
+
+func parseMetadata(s string) map[string]string {
+	meta := make(map[string]string)
+	if s == "" {
+		return meta
+	}
+	pairs := strings.Split(s, ",")
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 {
+			meta[parts[0]] = parts[1]
+		}
+	}
+	return meta
+}
@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd || dragonfly) && !windows
+
+package download
+
+import "errors"
+
+func availableBytes(dir string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd || dragonfly
+
+package download
+
+import "golang.org/x/sys/unix"
+
+// availableBytes reports the space available to unprivileged users on the
+// filesystem containing dir.
+func availableBytes(dir string) (int64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package download
+
+import "golang.org/x/sys/windows"
+
+// availableBytes reports the space available to the calling user on the
+// volume containing dir.
+func availableBytes(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return int64(free), nil
+}
@@ -0,0 +1,231 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/s3ops"
+)
+
+func downloadRecursive(ctx context.Context, base downloader, prefix, outputDir string, l layout) ([]string, int64, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	objects, err := s3ops.ListObjectsAll(ctx, base.client, base.bucket, prefix, base.listOpts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(objects) == 0 {
+		return nil, 0, fmt.Errorf("no objects found under s3://%s/%s", base.bucket, prefix)
+	}
+
+	fmt.Fprintf(base.out, "Found %d objects under s3://%s/%s\n\n", len(objects), base.bucket, prefix)
+
+	return downloadObjects(ctx, base, prefix, objects, outputDir, l)
+}
+
+// downloadGlob lists the literal prefix of pattern and downloads the keys
+// matching it with path.Match. Files are placed relative to the directory
+// portion of the literal prefix. "**" is not special: like "*", it never
+// matches across "/".
+func downloadGlob(ctx context.Context, base downloader, pattern, outputDir string, l layout) ([]string, int64, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	literal := s3uri.LiteralPrefix(pattern)
+	keyBase := ""
+	if idx := strings.LastIndex(literal, "/"); idx != -1 {
+		keyBase = literal[:idx+1]
+	}
+
+	listed, err := s3ops.ListObjectsByKeyPrefix(ctx, base.client, base.bucket, literal, base.listOpts...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var objects []s3ops.ObjectInfo
+	for _, obj := range listed {
+		if ok, _ := path.Match(pattern, obj.Key); ok {
+			objects = append(objects, obj)
+		}
+	}
+	if len(objects) == 0 {
+		return nil, 0, fmt.Errorf("no objects match s3://%s/%s", base.bucket, pattern)
+	}
+
+	fmt.Fprintf(base.out, "Matched %d objects for s3://%s/%s\n\n", len(objects), base.bucket, pattern)
+
+	return downloadObjects(ctx, base, keyBase, objects, outputDir, l)
+}
+
+// layout controls how keys are mapped onto local paths in recursive and
+// glob downloads.
+type layout struct {
+	// flatten writes every object directly into the output directory under
+	// its base name.
+	flatten bool
+	// stripPrefix, when set, replaces the listed prefix as the portion of
+	// each key removed before mapping.
+	stripPrefix string
+	// overwrite lets a later key replace an earlier one that maps to the
+	// same local path.
+	overwrite bool
+	// template, when set, renders each object's path below the output
+	// directory from its metadata.
+	template *template.Template
+}
+
+type plannedFile struct {
+	key       string
+	size      int64
+	localPath string
+	dir       bool
+}
+
+// plan maps objects onto outputDir and reports collisions and
+// file-vs-directory conflicts before anything is written. names holds the
+// rendered -output-template paths, if any.
+func (l layout) plan(keyBase string, objects []s3ops.ObjectInfo, outputDir string, names map[string]string) ([]plannedFile, error) {
+	var files []plannedFile
+	byPath := make(map[string]int)
+	for _, obj := range objects {
+		isDir := strings.HasSuffix(obj.Key, "/")
+		if isDir && (l.flatten || names != nil) {
+			continue
+		}
+
+		if !strings.HasPrefix(obj.Key, keyBase) {
+			return nil, fmt.Errorf("key %q does not start with -strip-prefix %q", obj.Key, keyBase)
+		}
+		rel := strings.TrimPrefix(obj.Key, keyBase)
+		switch {
+		case names != nil:
+			rel = names[obj.Key]
+		case l.flatten:
+			rel = path.Base(obj.Key)
+		}
+
+		localPath, err := localPathFor(outputDir, rel)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := byPath[localPath]; ok {
+			switch {
+			case isDir && files[i].dir:
+				continue
+			case isDir || files[i].dir:
+				return nil, fmt.Errorf("keys %q and %q map to %s as both a file and a directory", files[i].key, obj.Key, localPath)
+			case !l.overwrite:
+				return nil, fmt.Errorf("keys %q and %q both map to %s (use -overwrite to keep the last one)", files[i].key, obj.Key, localPath)
+			}
+			files[i] = plannedFile{key: obj.Key, size: obj.Size, localPath: localPath}
+			continue
+		}
+
+		byPath[localPath] = len(files)
+		files = append(files, plannedFile{key: obj.Key, size: obj.Size, localPath: localPath, dir: isDir})
+	}
+
+	for _, f := range files {
+		for dir := filepath.Dir(f.localPath); dir != outputDir && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if i, ok := byPath[dir]; ok && !files[i].dir {
+				return nil, fmt.Errorf("key %q needs directory %s, but key %q is downloaded to that path as a file", f.key, dir, files[i].key)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// downloadObjects downloads each object with a copy of base, mapping keys
+// below keyBase onto outputDir according to l. It returns the keys written.
+func downloadObjects(ctx context.Context, base downloader, keyBase string, objects []s3ops.ObjectInfo, outputDir string, l layout) ([]string, int64, error) {
+	if info, err := os.Stat(outputDir); err == nil && !info.IsDir() {
+		return nil, 0, fmt.Errorf("output %q exists and is not a directory", outputDir)
+	}
+
+	if l.stripPrefix != "" {
+		keyBase = l.stripPrefix
+	}
+
+	var names map[string]string
+	if l.template != nil {
+		var err error
+		names, err = renderNames(ctx, base, l.template, keyBase, objects)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	files, err := l.plan(keyBase, objects, filepath.Clean(outputDir), names)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var keys []string
+	var totalBytes int64
+	for i, f := range files {
+		if f.dir {
+			if err := os.MkdirAll(f.localPath, 0755); err != nil {
+				return keys, totalBytes, fmt.Errorf("failed to create directory %s: %w", f.localPath, err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(f.localPath), 0755); err != nil {
+			return keys, totalBytes, fmt.Errorf("failed to create directory for %s: %w", f.localPath, err)
+		}
+		if info, err := os.Stat(f.localPath); err == nil && info.IsDir() {
+			return keys, totalBytes, fmt.Errorf("%s: local path %s is a directory", f.key, f.localPath)
+		}
+
+		fmt.Fprintf(base.out, "[%d/%d] s3://%s/%s → %s\n", i+1, len(files), base.bucket, f.key, f.localPath)
+
+		d := base
+		d.key = f.key
+		d.outputPath = f.localPath
+		start := time.Now()
+		err := d.download(ctx)
+		if s3ops.ClassifyError(err) != s3ops.KindPrecondition {
+			var n int64
+			if err == nil {
+				n = f.size
+			}
+			base.log.Transfer("download", base.bucket, f.key, n, start, err)
+		}
+		if err != nil {
+			if s3ops.ClassifyError(err) == s3ops.KindPrecondition {
+				fmt.Fprintf(base.out, "Skipped: precondition not met\n\n")
+				continue
+			}
+			return keys, totalBytes, fmt.Errorf("%s: %w", f.key, err)
+		}
+		fmt.Fprintln(base.out)
+
+		keys = append(keys, f.key)
+		totalBytes += f.size
+	}
+
+	return keys, totalBytes, nil
+}
+
+// localPathFor maps a key relative to the download prefix onto outputDir,
+// refusing keys whose ".." segments would escape it.
+func localPathFor(outputDir, rel string) (string, error) {
+	localPath := filepath.Join(outputDir, filepath.FromSlash(rel))
+	relToRoot, err := filepath.Rel(outputDir, localPath)
+	if err != nil || relToRoot == ".." || strings.HasPrefix(relToRoot, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("key %q escapes output directory %q", rel, outputDir)
+	}
+	return localPath, nil
+}
@@ -1,22 +1,32 @@
 package download
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"text/template"
 	"time"
 
 	"s3-client/internal/s3uri"
 	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/metrics"
+	"s3-client/internal/shared/ratelimit"
+	"s3-client/internal/shared/report"
+	"s3-client/internal/shared/s3client"
 	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/time/rate"
 )
 
 func newFlagSet() *flag.FlagSet {
@@ -24,12 +34,30 @@ func newFlagSet() *flag.FlagSet {
 }
 
 func printUsage(fs *flag.FlagSet) {
-	fmt.Fprintln(os.Stderr, "Usage: s3-client download [flags] s3://bucket/key/path")
+	fmt.Fprintln(os.Stderr, "Usage: s3-client download [flags] s3://bucket/key/path | s3://bucket/prefix/")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Examples:")
 	fmt.Fprintln(os.Stderr, "  s3-client download s3://my-bucket/backups/file.tgz")
 	fmt.Fprintln(os.Stderr, "  s3-client download -profile prod -region us-west-2 s3://my-bucket/data/dump.tar.gz")
 	fmt.Fprintln(os.Stderr, "  s3-client download -chunk-size 25 -concurrency 8 -output /tmp/file.tgz s3://my-bucket/file.tgz")
+	fmt.Fprintln(os.Stderr, "  s3-client download -adaptive s3://my-bucket/data/huge.bin")
+	fmt.Fprintln(os.Stderr, "  s3-client download -output - s3://my-bucket/file.tgz | tar xz")
+	fmt.Fprintln(os.Stderr, "  s3-client download -recursive -output ./backups s3://my-bucket/backups/")
+	fmt.Fprintln(os.Stderr, "  s3-client download -output ./logs 's3://my-bucket/logs/2024-*.gz'")
+	fmt.Fprintln(os.Stderr, "  s3-client download -preserve-path -output ./mirror s3://my-bucket/data/2024/report.csv")
+	fmt.Fprintln(os.Stderr, "  s3-client download -recursive -output ./photos \\")
+	fmt.Fprintln(os.Stderr, "      -output-template '{{.LastModified.Format \"2006/01\"}}/{{.Base}}' s3://my-bucket/photos/")
+	fmt.Fprintln(os.Stderr, "  s3-client download -if-none-match '\"<etag>\"' s3://my-bucket/data.json")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Keys containing *, ? or [...] are matched with Go's path.Match; wildcards never")
+	fmt.Fprintln(os.Stderr, "cross \"/\" and \"**\" behaves like \"*\".")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "With -if-match, -if-none-match, -if-modified-since or -if-unmodified-since, an")
+	fmt.Fprintln(os.Stderr, "unmet condition exits with status 3 and leaves any local file untouched.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "An existing local file is not replaced without confirmation. With -quiet or")
+	fmt.Fprintln(os.Stderr, "without a terminal on stdin (cron jobs, scripts, pipes) the download fails")
+	fmt.Fprintln(os.Stderr, "instead; earlier versions overwrote silently, so such jobs now need -overwrite.")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Flags:")
 	fs.PrintDefaults()
@@ -37,13 +65,35 @@ func printUsage(fs *flag.FlagSet) {
 
 const defaultConcurrency = 5
 
+// defaultChunkRetries is how often a failed range is fetched again before the
+// whole download fails.
+const defaultChunkRetries = 3
+
+// partSuffix is appended to the output path while a download is in
+// progress.
+const partSuffix = ".part"
+
+// Bounds for -chunk-size 0: aim for about autoChunkTarget ranges, but never
+// fetch less than autoChunkMin or more than autoChunkMax per request.
 const (
-	stateWaiting = iota
-	stateDownloading
-	stateDone
-	stateFailed
+	autoChunkTarget = 200
+	autoChunkMin    = 5 * 1024 * 1024
+	autoChunkMax    = 512 * 1024 * 1024
 )
 
+// defaultMaxFailures is how many range requests may fail in a row, across
+// all workers, before the download stops retrying and reports S3 as
+// unavailable.
+const defaultMaxFailures = 10
+
+// adaptiveMaxConcurrency is the ceiling -adaptive ramps up to when
+// -concurrency is not given.
+const adaptiveMaxConcurrency = 16
+
+// exitPreconditionFailed is returned when -if-* conditions are not met (S3
+// answered 304 or 412); the local file is left untouched.
+const exitPreconditionFailed = 3
+
 type downloader struct {
 	client      *s3.Client
 	bucket      string
@@ -51,15 +101,23 @@ type downloader struct {
 	outputPath  string
 	chunkSize   int64
 	concurrency int
-}
-
-type chunk struct {
-	index int
-	start int64
-	end   int64
+	retries     int
+	maxFailures int
+	adaptive    bool
+	out         io.Writer
+	quiet       bool
+	overwrite   bool
+	keepPartial bool
+	spaceCheck  bool
+	sse         *s3ops.SSECustomerKey
+	cond        s3ops.Preconditions
+	log         *metrics.Log
+	limiter     *rate.Limiter
+	listOpts    []s3ops.ListOption
 }
 
 type progressBar struct {
+	w           io.Writer
 	mu          sync.Mutex
 	totalChunks int
 	totalBytes  int64
@@ -70,21 +128,28 @@ type progressBar struct {
 	lastTime    time.Time
 	speedMBs    float64
 	rendered    bool
+
+	// plain writes throttled one-line updates without escape codes, for
+	// output that is not a terminal.
+	plain bool
+	log   ui.ProgressLog
 }
 
-func newProgressBar(totalChunks int, totalBytes int64, downloaded *int64) *progressBar {
+func newProgressBar(w io.Writer, totalChunks int, totalBytes int64, downloaded *int64) *progressBar {
 	return &progressBar{
+		w:           w,
 		totalChunks: totalChunks,
 		totalBytes:  totalBytes,
 		chunkStates: make([]int32, totalChunks),
 		downloaded:  downloaded,
 		startTime:   time.Now(),
 		lastTime:    time.Now(),
+		plain:       !ui.IsTerminal(w),
 	}
 }
 
-func (p *progressBar) setState(chunkIdx int, state int32) {
-	atomic.StoreInt32(&p.chunkStates[chunkIdx], state)
+func (p *progressBar) setState(chunkIdx int, state s3ops.ChunkState) {
+	atomic.StoreInt32(&p.chunkStates[chunkIdx], int32(state))
 }
 
 func (p *progressBar) render() {
@@ -104,7 +169,10 @@ func (p *progressBar) render() {
 
 	totalMB := float64(p.totalBytes) / 1024 / 1024
 	doneMB := float64(bytes) / 1024 / 1024
-	pct := doneMB / totalMB * 100
+	pct := 100.0
+	if p.totalBytes > 0 {
+		pct = doneMB / totalMB * 100
+	}
 	if pct > 100 {
 		pct = 100
 	}
@@ -113,30 +181,42 @@ func (p *progressBar) render() {
 	if p.speedMBs > 0 && pct < 100 {
 		remainMB := totalMB - doneMB
 		etaSec := remainMB / p.speedMBs
-		etaStr = formatDuration(time.Duration(etaSec * float64(time.Second)))
+		etaStr = ui.FormatDuration(time.Duration(etaSec * float64(time.Second)))
 	}
 
 	waiting, downloading, done, failed := 0, 0, 0, 0
 	for i := range p.chunkStates {
-		switch atomic.LoadInt32(&p.chunkStates[i]) {
-		case stateWaiting:
+		switch s3ops.ChunkState(atomic.LoadInt32(&p.chunkStates[i])) {
+		case s3ops.ChunkWaiting:
 			waiting++
-		case stateDownloading:
+		case s3ops.ChunkDownloading:
 			downloading++
-		case stateDone:
+		case s3ops.ChunkDone:
 			done++
-		case stateFailed:
+		case s3ops.ChunkFailed:
 			failed++
 		}
 	}
 
+	if p.plain {
+		if p.log.Due(pct) {
+			fmt.Fprintf(p.w, "Progress: %5.1f%%  %.2f / %.2f MB  speed: %.2f MB/s  elapsed: %s  ETA: %s  chunks: %d/%d done",
+				pct, doneMB, totalMB, p.speedMBs, ui.FormatDuration(time.Since(p.startTime)), etaStr, done, p.totalChunks)
+			if failed > 0 {
+				fmt.Fprintf(p.w, ", %d failed", failed)
+			}
+			fmt.Fprintln(p.w)
+		}
+		return
+	}
+
 	numLines := 5
 	if p.rendered {
-		fmt.Printf("\033[%dA", numLines)
+		fmt.Fprintf(p.w, "\033[%dA", numLines)
 		for i := 0; i < numLines; i++ {
-			fmt.Print("\033[2K\n")
+			fmt.Fprint(p.w, "\033[2K\n")
 		}
-		fmt.Printf("\033[%dA", numLines)
+		fmt.Fprintf(p.w, "\033[%dA", numLines)
 	}
 	p.rendered = true
 
@@ -146,54 +226,90 @@ func (p *progressBar) render() {
 
 	totalElapsed := time.Since(p.startTime)
 
-	fmt.Printf("  Progress: %5.1f%%  [%s]  ETA: %s\n", pct, bar, etaStr)
-	fmt.Printf("  %.2f / %.2f MB   speed: %.2f MB/s   elapsed: %s\n",
-		doneMB, totalMB, p.speedMBs, formatDuration(totalElapsed))
-	fmt.Printf("  Chunks ▸ total: %d   ⬜ waiting: %d   🔄 active: %d   ✅ done: %d",
+	fmt.Fprintf(p.w, "  Progress: %5.1f%%  [%s]  ETA: %s\n", pct, bar, etaStr)
+	fmt.Fprintf(p.w, "  %.2f / %.2f MB   speed: %.2f MB/s   elapsed: %s\n",
+		doneMB, totalMB, p.speedMBs, ui.FormatDuration(totalElapsed))
+	fmt.Fprintf(p.w, "  Chunks ▸ total: %d   ⬜ waiting: %d   🔄 active: %d   ✅ done: %d",
 		p.totalChunks, waiting, downloading, done)
 	if failed > 0 {
-		fmt.Printf("   ❌ failed: %d", failed)
+		fmt.Fprintf(p.w, "   ❌ failed: %d", failed)
 	}
-	fmt.Println()
+	fmt.Fprintln(p.w)
 
-	fmt.Printf("  Chunk map (▓=done  ▒=active  ░=waiting  ✗=failed):\n")
-	fmt.Print("  [")
+	fmt.Fprintf(p.w, "  Chunk map (▓=done  ▒=active  ░=waiting  ✗=failed):\n")
+	fmt.Fprint(p.w, "  [")
 	for i := range p.chunkStates {
-		switch atomic.LoadInt32(&p.chunkStates[i]) {
-		case stateWaiting:
-			fmt.Print("░")
-		case stateDownloading:
-			fmt.Print("\033[33m▒\033[0m")
-		case stateDone:
-			fmt.Print("\033[32m▓\033[0m")
-		case stateFailed:
-			fmt.Print("\033[31m✗\033[0m")
+		switch s3ops.ChunkState(atomic.LoadInt32(&p.chunkStates[i])) {
+		case s3ops.ChunkWaiting:
+			fmt.Fprint(p.w, "░")
+		case s3ops.ChunkDownloading:
+			fmt.Fprint(p.w, "\033[33m▒\033[0m")
+		case s3ops.ChunkDone:
+			fmt.Fprint(p.w, "\033[32m▓\033[0m")
+		case s3ops.ChunkFailed:
+			fmt.Fprint(p.w, "\033[31m✗\033[0m")
 		}
 	}
-	fmt.Println("]")
+	fmt.Fprintln(p.w, "]")
 }
 
-func formatDuration(d time.Duration) string {
-	d = d.Round(time.Second)
-	h := d / time.Hour
-	d -= h * time.Hour
-	m := d / time.Minute
-	d -= m * time.Minute
-	s := d / time.Second
-	if h > 0 {
-		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
+// checkDiskSpace fails if the filesystem holding outputPath cannot fit size
+// bytes. The download is written to outputPath+partSuffix, which is
+// truncated first, so a leftover .part file's space counts as available; an
+// existing outputPath is only replaced once the download is complete, so its
+// space does not. Filesystems that cannot be queried are not checked.
+func checkDiskSpace(outputPath string, size int64) error {
+	avail, err := availableBytes(filepath.Dir(outputPath))
+	if err != nil {
+		return nil
+	}
+	if info, err := os.Stat(outputPath + partSuffix); err == nil && info.Mode().IsRegular() {
+		avail += info.Size()
 	}
-	if m > 0 {
-		return fmt.Sprintf("%dm%02ds", m, s)
+	if size > avail {
+		return fmt.Errorf("not enough disk space for %s: need %s, %s available (use -no-space-check to skip)",
+			outputPath, ui.FormatSize(size), ui.FormatSize(avail))
 	}
-	return fmt.Sprintf("%ds", s)
+	return nil
+}
+
+// flagSet reports whether name was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
 }
 
 func Run(args []string) int {
 	fs := newFlagSet()
-	output := fs.String("output", "", "Output file path (defaults to basename of the S3 key)")
-	chunkMB := fs.Int("chunk-size", 10, "Chunk size in MB")
-	concurrency := fs.Int("concurrency", defaultConcurrency, "Number of parallel chunk downloads")
+	output := fs.String("output", "", "Output file path, or - for stdout (defaults to basename of the S3 key)")
+	chunkMB := fs.Int("chunk-size", 10, "Chunk size in MB (0 picks one from the object size)")
+	concurrency := fs.Int("concurrency", defaultConcurrency, "Number of parallel chunk downloads (the ceiling with -adaptive)")
+	chunkRetries := fs.Int("chunk-retries", defaultChunkRetries, "Retry a failed chunk this many times, with backoff, before failing the download")
+	maxFailures := fs.Int("max-failures", defaultMaxFailures, "Give up with \"S3 appears unavailable\" after this many consecutive failed range requests across all workers (0 disables)")
+	adaptive := fs.Bool("adaptive", false, "Start with few workers and add more while throughput improves, backing off when throttled")
+	recursive := fs.Bool("recursive", false, "Download every object under the given prefix into the -output directory")
+	flatten := fs.Bool("flatten", false, "With -recursive or a wildcard, write all files directly into -output by base name")
+	stripPrefix := fs.String("strip-prefix", "", "With -recursive or a wildcard, key prefix to remove before mapping to local paths")
+	overwrite := fs.Bool("overwrite", false, "Replace existing local files without asking (needed for them with -quiet or when stdin is not a terminal); with -flatten or -output-template, also let later keys replace earlier ones with the same name")
+	outputTemplate := fs.String("output-template", "", "With -recursive or a wildcard, Go template for each local path (fields: .Key .Base .Rel .Size .LastModified .ContentType .ETag .StorageClass .Metadata)")
+	sseKey := fs.String("sse-c-key", "", "SSE-C key for encrypted objects: path to a key file or a base64-encoded 32-byte key")
+	ifMatch := fs.String("if-match", "", "Only download if the object's ETag matches")
+	ifNoneMatch := fs.String("if-none-match", "", "Only download if the object's ETag differs")
+	ifModifiedSince := fs.String("if-modified-since", "", "Only download if modified after this RFC3339 time")
+	ifUnmodifiedSince := fs.String("if-unmodified-since", "", "Only download if not modified after this RFC3339 time")
+	maxRate := fs.String("max-rate", "", "Cap total download bandwidth across all workers (e.g. 500KB, 10MB, 1G per second)")
+	logFile := fs.String("log-file", "", "Append a JSON-lines record per downloaded object to this file")
+	keepPartial := fs.Bool("keep-partial", false, "Keep the incomplete <output>.part file when a download fails")
+	noSpaceCheck := fs.Bool("no-space-check", false, "Skip checking free disk space before downloading")
+	preservePath := fs.Bool("preserve-path", false, "For a single object, treat -output as a directory and write to <output>/<full key>, like -recursive does")
+
+	listFlags := &config.ListFlags{}
+	config.AddListFlags(fs, listFlags)
 
 	opts := &config.Options{}
 	config.AddFlags(fs, opts)
@@ -211,17 +327,139 @@ func Run(args []string) int {
 		return 1
 	}
 
-	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	var bucket, key string
+	var err error
+	if *recursive {
+		bucket, key, err = s3uri.ParsePrefix(fs.Arg(0))
+	} else {
+		bucket, key, err = s3uri.Parse(fs.Arg(0))
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	glob := !*recursive && s3uri.HasWildcard(key)
+
+	if *chunkRetries < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -chunk-retries must not be negative")
+		return 1
+	}
+	if *chunkMB < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -chunk-size must not be negative")
+		return 1
+	}
+	if *maxFailures < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -max-failures must not be negative")
+		return 1
+	}
+	if err := listFlags.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var tmpl *template.Template
+	if *preservePath && (*recursive || glob) {
+		fmt.Fprintln(os.Stderr, "Error: -preserve-path only applies to single-object downloads; -recursive and wildcards already keep key paths")
+		return 1
+	}
+
+	if *outputTemplate != "" {
+		if !*recursive && !glob {
+			fmt.Fprintln(os.Stderr, "Error: -output-template requires -recursive or a wildcard key")
+			return 1
+		}
+		if *flatten {
+			fmt.Fprintln(os.Stderr, "Error: -output-template and -flatten cannot be combined")
+			return 1
+		}
+		tmpl, err = parseOutputTemplate(*outputTemplate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if *adaptive && !flagSet(fs, "concurrency") {
+		*concurrency = adaptiveMaxConcurrency
+	}
+
+	var sse *s3ops.SSECustomerKey
+	if *sseKey != "" {
+		sse, err = s3ops.ParseSSECustomerKey(*sseKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	var limiter *rate.Limiter
+	if *maxRate != "" {
+		bytesPerSec, err := ratelimit.ParseRate(*maxRate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -max-rate: %v\n", err)
+			return 1
+		}
+		limiter = ratelimit.New(bytesPerSec)
+	}
+
+	cond := s3ops.Preconditions{IfMatch: *ifMatch, IfNoneMatch: *ifNoneMatch}
+	for _, t := range []struct {
+		flag  string
+		value string
+		dst   **time.Time
+	}{
+		{"-if-modified-since", *ifModifiedSince, &cond.IfModifiedSince},
+		{"-if-unmodified-since", *ifUnmodifiedSince, &cond.IfUnmodifiedSince},
+	} {
+		if t.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, t.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s must be an RFC3339 time: %v\n", t.flag, err)
+			return 1
+		}
+		*t.dst = &parsed
+	}
+
 	outputPath := *output
-	if outputPath == "" {
+	if *recursive || glob {
+		if outputPath == "-" {
+			fmt.Fprintln(os.Stderr, "Error: -output - cannot be combined with -recursive or a wildcard key")
+			return 1
+		}
+		if outputPath == "" {
+			outputPath = "."
+		}
+	} else if *preservePath {
+		if outputPath == "-" {
+			fmt.Fprintln(os.Stderr, "Error: -preserve-path cannot be combined with -output -")
+			return 1
+		}
+		if outputPath == "" {
+			outputPath = "."
+		}
+		outputPath, err = localPathFor(filepath.Clean(outputPath), key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else if outputPath == "" {
 		outputPath = filepath.Base(key)
 	}
 
+	toStdout := outputPath == "-"
+	if toStdout && opts.JSON {
+		fmt.Fprintln(os.Stderr, "Error: -json cannot be combined with -output -")
+		return 1
+	}
+
+	out := opts.Out()
+	if toStdout && out == os.Stdout {
+		out = os.Stderr
+	}
+
 	ctx := context.Background()
 	cfg, err := config.Load(ctx, *opts)
 	if err != nil {
@@ -239,140 +477,350 @@ func Run(args []string) int {
 		return 1
 	}
 	if opts.Profile != "" {
-		fmt.Printf("Using AWS profile: %s (source: %s)\n", opts.Profile, creds.Source)
+		fmt.Fprintf(out, "Using AWS profile: %s (source: %s)\n", opts.Profile, creds.Source)
 	}
 
-	client := s3.NewFromConfig(cfg)
-	d := &downloader{
+	transferLog := metrics.Open(*logFile)
+	defer transferLog.Close()
+
+	poolCfg := s3client.ForConcurrency(*concurrency)
+	client := s3client.NewClientFromConfig(cfg, poolCfg)
+	factory := s3client.NewFactoryWithConfig(poolCfg)
+
+	if toStdout {
+		start := time.Now()
+		var written int64
+		err := regionRetry(ctx, factory, *opts, bucket, client, func(c *s3.Client) error {
+			if !cond.IsZero() {
+				if err := s3ops.CheckPreconditions(ctx, c, bucket, key, cond, sse); err != nil {
+					return err
+				}
+			}
+			w := ratelimit.NewWriter(ctx, os.Stdout, limiter)
+			return s3ops.DownloadObjectTo(ctx, c, bucket, key, w, func(p s3ops.DownloadProgress) {
+				written = p.DownloadedBytes
+			}, s3ops.WithSSECustomerKey(sse))
+		})
+		transferLog.Transfer("download", bucket, key, written, start, err)
+		if s3ops.ClassifyError(err) == s3ops.KindPrecondition {
+			fmt.Fprintf(os.Stderr, "Skipped s3://%s/%s: precondition not met\n", bucket, key)
+			return exitPreconditionFailed
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Download failed: %v\n", err)
+			printErrorTip(err, bucket, key)
+			return 1
+		}
+		return 0
+	}
+
+	d := downloader{
 		client:      client,
 		bucket:      bucket,
 		key:         key,
 		outputPath:  outputPath,
 		chunkSize:   int64(*chunkMB) * 1024 * 1024,
 		concurrency: *concurrency,
+		retries:     *chunkRetries,
+		maxFailures: *maxFailures,
+		adaptive:    *adaptive,
+		out:         out,
+		quiet:       opts.Quiet,
+		overwrite:   *overwrite,
+		keepPartial: *keepPartial,
+		spaceCheck:  !*noSpaceCheck,
+		sse:         sse,
+		cond:        cond,
+		log:         transferLog,
+		limiter:     limiter,
+		listOpts:    listFlags.Options(),
 	}
 
-	fmt.Printf("Downloading  s3://%s/%s\n", bucket, key)
-	fmt.Printf("Output       %s\n", outputPath)
-	fmt.Printf("Chunk size   %d MB  |  Concurrency: %d\n\n", *chunkMB, *concurrency)
+	if *recursive || glob {
+		l := layout{flatten: *flatten, stripPrefix: *stripPrefix, overwrite: *overwrite, template: tmpl}
+		start := time.Now()
+		var keys []string
+		var bytes int64
+		err := regionRetry(ctx, factory, *opts, bucket, client, func(c *s3.Client) error {
+			d.client = c
+			var err error
+			if glob {
+				keys, bytes, err = downloadGlob(ctx, d, key, outputPath, l)
+			} else {
+				keys, bytes, err = downloadRecursive(ctx, d, key, outputPath, l)
+			}
+			return err
+		})
+		if opts.JSON {
+			result := report.New("download", bucket, start, err)
+			result.Keys = keys
+			result.Bytes = bytes
+			report.Write(os.Stdout, result)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\n❌ Download failed after %d files: %v\n", len(keys), err)
+			printErrorTip(err, bucket, key)
+			return 1
+		}
+		elapsed := time.Since(start)
+		sizeMB := float64(bytes) / 1024 / 1024
+		fmt.Fprintf(out, "✓ Done! %d files, %.2f MB in %s\n", len(keys), sizeMB, ui.FormatDuration(elapsed))
+		return 0
+	}
+
+	if *preservePath {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create directory for %s: %v\n", outputPath, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(out, "Downloading  s3://%s/%s\n", bucket, key)
+	fmt.Fprintf(out, "Output       %s\n", outputPath)
+	chunkDesc := fmt.Sprintf("%d MB", *chunkMB)
+	if *chunkMB == 0 {
+		chunkDesc = "auto"
+	}
+	if *adaptive {
+		fmt.Fprintf(out, "Chunk size   %s  |  Concurrency: adaptive, up to %d\n\n", chunkDesc, *concurrency)
+	} else {
+		fmt.Fprintf(out, "Chunk size   %s  |  Concurrency: %d\n\n", chunkDesc, *concurrency)
+	}
 
 	start := time.Now()
-	if err := d.download(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "\n❌ Download failed: %v\n", err)
-		if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "AccessDenied") {
-			fmt.Fprintln(os.Stderr, "Tip: 403/AccessDenied — credentials lack s3:GetObject on this bucket/key.")
-		} else if strings.Contains(err.Error(), "NoSuchKey") {
-			fmt.Fprintf(os.Stderr, "Tip: key %q not found in bucket %q.\n", key, bucket)
-		} else if strings.Contains(err.Error(), "400") {
-			fmt.Fprintln(os.Stderr, "Tip: 400 Bad Request — bucket may be in a different region. Try -region <region>.")
+	err = regionRetry(ctx, factory, *opts, bucket, client, func(c *s3.Client) error {
+		d.client = c
+		return d.download(ctx)
+	})
+	if s3ops.ClassifyError(err) == s3ops.KindPrecondition {
+		if opts.JSON {
+			report.Write(os.Stdout, report.New("download", bucket, start, err))
+		}
+		fmt.Fprintf(out, "Skipped: precondition not met, %s left untouched\n", outputPath)
+		return exitPreconditionFailed
+	}
+	if err != nil {
+		if opts.JSON {
+			report.Write(os.Stdout, report.New("download", bucket, start, err))
 		}
+		transferLog.Transfer("download", bucket, key, 0, start, err)
+		fmt.Fprintf(os.Stderr, "\n❌ Download failed: %v\n", err)
+		printErrorTip(err, bucket, key)
 		return 1
 	}
 
 	elapsed := time.Since(start)
 	info, _ := os.Stat(outputPath)
+	transferLog.Transfer("download", bucket, key, info.Size(), start, nil)
+	if opts.JSON {
+		result := report.New("download", bucket, start, nil)
+		result.Keys = []string{key}
+		result.Bytes = info.Size()
+		report.Write(os.Stdout, result)
+	}
 	sizeMB := float64(info.Size()) / 1024 / 1024
-	fmt.Printf("\n✓ Done! %.2f MB in %s (avg %.2f MB/s)\n",
-		sizeMB, formatDuration(elapsed), sizeMB/elapsed.Seconds())
+	fmt.Fprintf(out, "\n✓ Done! %.2f MB in %s (avg %.2f MB/s)\n",
+		sizeMB, ui.FormatDuration(elapsed), sizeMB/elapsed.Seconds())
 	return 0
 }
 
+func printErrorTip(err error, bucket, key string) {
+	if errors.Is(err, s3ops.ErrUnavailable) {
+		fmt.Fprintln(os.Stderr, "Tip: every recent request failed — check your network or VPN connection and try again.")
+		return
+	}
+	if errors.Is(err, s3ops.ErrRangeMismatch) {
+		fmt.Fprintln(os.Stderr, "Tip: the endpoint does not serve byte ranges correctly; try -output - to stream the object in one request.")
+		return
+	}
+	switch s3ops.ClassifyError(err) {
+	case s3ops.KindAccessDenied:
+		fmt.Fprintln(os.Stderr, "Tip: 403/AccessDenied — credentials lack s3:GetObject on this bucket/key.")
+	case s3ops.KindNotFound:
+		fmt.Fprintf(os.Stderr, "Tip: key %q not found in bucket %q.\n", key, bucket)
+	case s3ops.KindWrongRegion:
+		fmt.Fprintln(os.Stderr, "Tip: bucket is in a different region. Try -region <region>.")
+	case s3ops.KindThrottled:
+		fmt.Fprintln(os.Stderr, "Tip: S3 is throttling requests — retry later or lower -concurrency.")
+	case s3ops.KindArchived:
+		fmt.Fprintf(os.Stderr, "Tip: object is archived. Run: s3-client restore s3://%s/%s\n", bucket, key)
+	}
+}
+
+// regionRetry runs fn and, if S3 reports that bucket lives in a region other
+// than the configured one, retries once with a region-correct client.
+func regionRetry(ctx context.Context, factory *s3client.Factory, opts config.Options, bucket string, client *s3.Client, fn func(*s3.Client) error) error {
+	err := fn(client)
+	if err == nil || !s3ops.IsWrongRegion(err) {
+		return err
+	}
+
+	regionClient, rerr := factory.GetClientForBucket(ctx, opts, bucket)
+	if rerr != nil {
+		return fmt.Errorf("%w (bucket region lookup failed: %v)", err, rerr)
+	}
+	fmt.Fprintf(os.Stderr, "Bucket %s is in region %s, retrying there\n", bucket, regionClient.Options().Region)
+	return fn(regionClient)
+}
+
 func (d *downloader) download(ctx context.Context) error {
-	meta, err := s3ops.HeadObject(ctx, d.client, d.bucket, d.key)
+	if !d.cond.IsZero() {
+		if err := s3ops.CheckPreconditions(ctx, d.client, d.bucket, d.key, d.cond, d.sse); err != nil {
+			return err
+		}
+	}
+
+	meta, err := s3ops.HeadObject(ctx, d.client, d.bucket, d.key, s3ops.WithSSECustomerKey(d.sse))
 	if err != nil {
 		return fmt.Errorf("HeadObject failed: %w", err)
 	}
 	totalSize := meta.Size
-	fmt.Printf("Object size: %.2f MB (%d bytes)\n", float64(totalSize)/1024/1024, totalSize)
+	fmt.Fprintf(d.out, "Object size: %.2f MB (%d bytes)\n", float64(totalSize)/1024/1024, totalSize)
+
+	if !d.overwrite {
+		if err := confirmOverwrite(d.outputPath, d.quiet); err != nil {
+			return err
+		}
+	}
+
+	if d.spaceCheck {
+		if err := checkDiskSpace(d.outputPath, totalSize); err != nil {
+			return err
+		}
+	}
 
-	f, err := os.OpenFile(d.outputPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	// Write to a .part file and rename it into place only once every byte
+	// has arrived, so the output path never holds a half-written download.
+	partPath := d.outputPath + partSuffix
+	f, err := os.OpenFile(partPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer f.Close()
 
-	if err := f.Truncate(totalSize); err != nil {
-		return fmt.Errorf("failed to pre-allocate file: %w", err)
+	chunkSize := d.chunkSize
+	if chunkSize == 0 {
+		chunkSize = autoChunkSize(totalSize)
+		fmt.Fprintf(d.out, "Chunk size: %d MB (auto)\n", chunkSize/(1024*1024))
 	}
 
-	var chunks []chunk
-	for i := int64(0); i < totalSize; i += d.chunkSize {
-		end := i + d.chunkSize - 1
-		if end >= totalSize {
-			end = totalSize - 1
+	err = d.fetch(ctx, f, totalSize, chunkSize)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to write output file: %w", cerr)
+	}
+	if err != nil {
+		if d.keepPartial {
+			fmt.Fprintf(os.Stderr, "Partial download kept at %s\n", partPath)
+		} else {
+			os.Remove(partPath)
 		}
-		chunks = append(chunks, chunk{index: len(chunks), start: i, end: end})
+		return err
 	}
-	totalChunks := len(chunks)
-	fmt.Printf("Splitting into %d chunks\n\n", totalChunks)
-
-	var downloaded int64
-	pb := newProgressBar(totalChunks, totalSize, &downloaded)
 
-	ticker := time.NewTicker(150 * time.Millisecond)
-	stopProgress := make(chan struct{})
-	progressDone := make(chan struct{})
-	go func() {
-		defer close(progressDone)
-		for {
-			select {
-			case <-ticker.C:
-				pb.render()
-			case <-stopProgress:
-				ticker.Stop()
-				pb.render()
-				return
-			}
-		}
-	}()
+	if err := os.Rename(partPath, d.outputPath); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return nil
+}
 
-	chunkCh := make(chan chunk, totalChunks)
-	for _, c := range chunks {
-		chunkCh <- c
+// fetch downloads the object's totalSize bytes into f in chunkSize ranges.
+func (d *downloader) fetch(ctx context.Context, f *os.File, totalSize, chunkSize int64) error {
+	// Zero-byte objects have no ranges to fetch; the empty file is the
+	// complete download.
+	if totalSize == 0 {
+		return nil
 	}
-	close(chunkCh)
 
-	errCh := make(chan error, d.concurrency)
-	var wg sync.WaitGroup
+	if err := f.Truncate(totalSize); err != nil {
+		return fmt.Errorf("failed to pre-allocate file: %w", err)
+	}
 
-	for i := 0; i < d.concurrency; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for c := range chunkCh {
-				pb.setState(c.index, stateDownloading)
-
-				data, err := s3ops.DownloadRange(ctx, d.client, d.bucket, d.key, s3ops.RangeDownload{
-					Start: c.start,
-					End:   c.end,
-				})
-				if err != nil {
-					pb.setState(c.index, stateFailed)
-					errCh <- fmt.Errorf("chunk %d (%d-%d) DownloadRange failed: %w", c.index, c.start, c.end, err)
+	var downloaded int64
+	cd := &s3ops.ChunkedDownloader{
+		Client:                 d.client,
+		ChunkSize:              chunkSize,
+		Concurrency:            d.concurrency,
+		Retries:                d.retries,
+		MaxConsecutiveFailures: d.maxFailures,
+		Adaptive:               d.adaptive,
+		SSECustomerKey:         d.sse,
+		Limiter:                d.limiter,
+		// Workers report concurrently, so keep the largest total seen.
+		Progress: func(p s3ops.DownloadProgress) {
+			for {
+				cur := atomic.LoadInt64(&downloaded)
+				if p.DownloadedBytes <= cur || atomic.CompareAndSwapInt64(&downloaded, cur, p.DownloadedBytes) {
 					return
 				}
+			}
+		},
+	}
 
-				if _, err := f.WriteAt(data, c.start); err != nil {
-					pb.setState(c.index, stateFailed)
-					errCh <- fmt.Errorf("chunk %d write failed: %w", c.index, err)
+	totalChunks := cd.NumChunks(totalSize)
+	fmt.Fprintf(d.out, "Splitting into %d chunks\n\n", totalChunks)
+
+	pb := newProgressBar(d.out, totalChunks, totalSize, &downloaded)
+	cd.OnChunk = pb.setState
+
+	stopProgress := make(chan struct{})
+	progressDone := make(chan struct{})
+	if d.quiet {
+		close(progressDone)
+	} else {
+		ticker := time.NewTicker(150 * time.Millisecond)
+		go func() {
+			defer close(progressDone)
+			for {
+				select {
+				case <-ticker.C:
+					pb.render()
+				case <-stopProgress:
+					ticker.Stop()
+					pb.render()
 					return
 				}
-
-				atomic.AddInt64(&downloaded, int64(len(data)))
-				pb.setState(c.index, stateDone)
 			}
 		}()
 	}
 
-	wg.Wait()
+	err := cd.Download(ctx, d.bucket, d.key, f, totalSize)
+
 	close(stopProgress)
 	<-progressDone
-	close(errCh)
+	return err
+}
 
-	for err := range errCh {
-		if err != nil {
-			return err
-		}
+// confirmOverwrite asks before an existing file at path is replaced. Without
+// a terminal on stdin, or with -quiet, there is nobody to ask and the
+// download is refused; -overwrite skips the check.
+func confirmOverwrite(path string, quiet bool) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
 	}
-	return nil
+	refused := fmt.Errorf("%s already exists (use -overwrite to replace it)", path)
+	if quiet || !stdinIsTerminal() {
+		return refused
+	}
+
+	fmt.Fprintf(os.Stderr, "%s already exists. Overwrite? [y/N] ", path)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return refused
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// autoChunkSize picks a chunk size for an object of size bytes: about
+// autoChunkTarget chunks, clamped to [autoChunkMin, autoChunkMax] and rounded
+// up to a whole MB.
+func autoChunkSize(size int64) int64 {
+	const mb = 1024 * 1024
+	chunk := (size + autoChunkTarget - 1) / autoChunkTarget
+	chunk = (chunk + mb - 1) / mb * mb
+	return min(max(chunk, autoChunkMin), autoChunkMax)
 }
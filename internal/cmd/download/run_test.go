@@ -0,0 +1,192 @@
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// newTestClient returns an unsigned client without SDK retries whose
+// requests all go to a server holding one object with content data.
+func newTestClient(t *testing.T, data []byte) *s3.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			t.Errorf("unexpected GET without a byte range: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		end = min(end, len(data)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[start : end+1])
+	}))
+	t.Cleanup(srv.Close)
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+func newTestDownloader(t *testing.T, data []byte, outputPath string) *downloader {
+	return &downloader{
+		client:     newTestClient(t, data),
+		bucket:     "bucket",
+		key:        "key",
+		outputPath: outputPath,
+		out:        io.Discard,
+		quiet:      true,
+		overwrite:  true,
+	}
+}
+
+func TestDownloadZeroByteObject(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "empty")
+	// A previous, larger file at the output path must end up empty.
+	if err := os.WriteFile(out, []byte("old content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloader(t, nil, out)
+	if err := d.download(t.Context()); err != nil {
+		t.Fatalf("download() = %v", err)
+	}
+
+	info, err := os.Stat(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("output size = %d, want 0", info.Size())
+	}
+	if _, err := os.Stat(out + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("%s left behind (stat: %v)", out+partSuffix, err)
+	}
+}
+
+func TestFetchZeroBytes(t *testing.T) {
+	if n := (&s3ops.ChunkedDownloader{}).NumChunks(0); n != 0 {
+		t.Errorf("NumChunks(0) = %d, want 0", n)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "part"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// No request may be made for an empty object: the test server fails
+	// any GET without a range, and there is no range to ask for.
+	d := newTestDownloader(t, nil, f.Name())
+	if err := d.fetch(t.Context(), f, 0, autoChunkSize(0)); err != nil {
+		t.Fatalf("fetch() = %v", err)
+	}
+	if info, _ := f.Stat(); info.Size() != 0 {
+		t.Errorf("file size = %d, want 0", info.Size())
+	}
+}
+
+func TestDownloadSmallObject(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	out := filepath.Join(t.TempDir(), "object")
+
+	d := newTestDownloader(t, data, out)
+	d.chunkSize = 4096
+	d.concurrency = 2
+	if err := d.download(t.Context()); err != nil {
+		t.Fatalf("download() = %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes that differ from the object", len(got))
+	}
+}
+
+func TestProgressBarZeroBytes(t *testing.T) {
+	var downloaded int64
+	var buf bytes.Buffer
+	pb := newProgressBar(&buf, 0, 0, &downloaded)
+	pb.render()
+	if s := buf.String(); strings.Contains(s, "NaN") || !strings.Contains(s, "100.0%") {
+		t.Errorf("render() for an empty object = %q, want 100.0%% and no NaN", s)
+	}
+}
+
+func TestAutoChunkSize(t *testing.T) {
+	const mb = 1024 * 1024
+	const gb = 1024 * mb
+	tests := []struct {
+		size int64
+		want int64
+	}{
+		{0, autoChunkMin},
+		{1, autoChunkMin},
+		{100 * mb, autoChunkMin},
+		{1 * gb, 6 * mb},
+		{2000 * mb, 10 * mb},
+		{2000*mb + 1, 11 * mb},
+		{100 * gb, autoChunkMax},
+		{1024 * gb, autoChunkMax},
+	}
+	for _, tt := range tests {
+		if got := autoChunkSize(tt.size); got != tt.want {
+			t.Errorf("autoChunkSize(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDiskSpaceCreditsOnlyPartFile(t *testing.T) {
+	dir := t.TempDir()
+	avail, err := availableBytes(dir)
+	if err != nil {
+		t.Skipf("free space not available here: %v", err)
+	}
+	const gb = 1 << 30
+	out := filepath.Join(dir, "object.bin")
+	// Sparse files make a large existing file without using the space.
+	sparse := func(name string) {
+		t.Helper()
+		f, err := os.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := f.Truncate(avail + 2*gb); err != nil {
+			t.Skipf("cannot create sparse file: %v", err)
+		}
+	}
+
+	sparse(out)
+	if err := checkDiskSpace(out, avail+gb); err == nil {
+		t.Error("checkDiskSpace credited the existing output file, which stays until the rename")
+	}
+
+	sparse(out + partSuffix)
+	if err := checkDiskSpace(out, avail+gb); err != nil {
+		t.Errorf("checkDiskSpace did not credit the .part file that will be truncated: %v", err)
+	}
+}
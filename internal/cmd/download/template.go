@@ -0,0 +1,101 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"s3-client/internal/shared/s3ops"
+)
+
+// templateData is what an -output-template is evaluated against for each
+// object.
+type templateData struct {
+	Key          string
+	Base         string
+	Rel          string
+	Size         int64
+	LastModified time.Time
+	ContentType  string
+	ETag         string
+	StorageClass string
+	Metadata     map[string]string
+}
+
+// parseOutputTemplate parses s and runs it once against sample data so
+// unknown fields and bad function calls fail before anything is downloaded.
+func parseOutputTemplate(s string) (*template.Template, error) {
+	t, err := template.New("output").Option("missingkey=zero").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -output-template: %w", err)
+	}
+	sample := templateData{
+		Key:          "prefix/file.txt",
+		Base:         "file.txt",
+		Rel:          "file.txt",
+		LastModified: time.Now(),
+		Metadata:     map[string]string{},
+	}
+	if err := t.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("invalid -output-template: %w", err)
+	}
+	return t, nil
+}
+
+// renderTemplate evaluates t for one object and returns a cleaned,
+// slash-separated path relative to the output directory. Empty, absolute and
+// escaping ("..") results are rejected.
+func renderTemplate(t *template.Template, data templateData) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	rendered := strings.TrimSpace(b.String())
+	rel := path.Clean(strings.ReplaceAll(rendered, "\\", "/"))
+	switch {
+	case rendered == "" || rel == ".":
+		return "", errors.New("renders an empty path")
+	case path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../"):
+		return "", fmt.Errorf("renders %q, which is outside the output directory", rendered)
+	}
+	return rel, nil
+}
+
+// renderNames heads every object under keyBase and renders its local path
+// with t. Directory markers are skipped.
+func renderNames(ctx context.Context, base downloader, t *template.Template, keyBase string, objects []s3ops.ObjectInfo) (map[string]string, error) {
+	names := make(map[string]string, len(objects))
+	for _, obj := range objects {
+		if strings.HasSuffix(obj.Key, "/") {
+			continue
+		}
+		meta, err := s3ops.HeadObject(ctx, base.client, base.bucket, obj.Key, s3ops.WithSSECustomerKey(base.sse))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", obj.Key, err)
+		}
+
+		data := templateData{
+			Key:          obj.Key,
+			Base:         path.Base(obj.Key),
+			Rel:          strings.TrimPrefix(obj.Key, keyBase),
+			Size:         meta.Size,
+			ContentType:  meta.ContentType,
+			ETag:         strings.Trim(meta.ETag, `"`),
+			LastModified: meta.LastModified,
+			StorageClass: meta.StorageClass,
+			Metadata:     meta.Metadata,
+		}
+
+		name, err := renderTemplate(t, data)
+		if err != nil {
+			return nil, fmt.Errorf("-output-template for %s: %w", obj.Key, err)
+		}
+		names[obj.Key] = name
+	}
+	return names, nil
+}
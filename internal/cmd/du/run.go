@@ -0,0 +1,188 @@
+package du
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/report"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("du", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client du [flags] s3://bucket[/prefix]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Summarise the total size and object count under a prefix.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client du s3://my-bucket/logs/")
+	fmt.Fprintln(os.Stderr, "  s3-client du -depth 1 s3://my-bucket/")
+	fmt.Fprintln(os.Stderr, "  s3-client du -group-by-storage-class s3://my-bucket/archive/")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+// usage accumulates size and object count for one group of keys.
+type usage struct {
+	bytes   int64
+	objects int
+}
+
+func (u *usage) add(size int64) {
+	u.bytes += size
+	u.objects++
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	depth := fs.Int("depth", 0, "Also report totals per sub-prefix, N levels below the prefix (like du -d N)")
+	byClass := fs.Bool("group-by-storage-class", false, "Break the total down by storage class")
+	concurrency := fs.Int("concurrency", 4, "List this many sub-prefixes in parallel")
+
+	listFlags := &config.ListFlags{}
+	config.AddListFlags(fs, listFlags)
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *depth < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -depth must not be negative")
+		return 1
+	}
+	if *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -concurrency must be at least 1")
+		return 1
+	}
+	if err := listFlags.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	bucket, prefix, err := s3uri.ParsePrefix(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	start := time.Now()
+	objects, err := s3ops.ListObjectsAllConcurrent(ctx, client, bucket, prefix, *concurrency, listFlags.Options()...)
+	if err != nil {
+		if opts.JSON {
+			report.Write(os.Stdout, report.New("du", bucket, start, err))
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var total usage
+	groups := make(map[string]*usage)
+	classes := make(map[string]*usage)
+	for _, obj := range objects {
+		total.add(obj.Size)
+
+		if *depth > 0 {
+			if group, ok := groupFor(obj.Key, prefix, *depth); ok {
+				if groups[group] == nil {
+					groups[group] = &usage{}
+				}
+				groups[group].add(obj.Size)
+			}
+		}
+
+		if *byClass {
+			class := obj.StorageClass
+			if class == "" {
+				class = "STANDARD"
+			}
+			if classes[class] == nil {
+				classes[class] = &usage{}
+			}
+			classes[class].add(obj.Size)
+		}
+	}
+
+	if opts.JSON {
+		result := report.New("du", bucket, start, nil)
+		result.Bytes = total.bytes
+		report.Write(os.Stdout, result)
+	}
+
+	out := opts.Out()
+	for _, name := range sortedKeys(groups) {
+		printUsageLine(out, *groups[name], fmt.Sprintf("s3://%s/%s", bucket, name))
+	}
+	printUsageLine(out, total, fmt.Sprintf("s3://%s/%s", bucket, prefix))
+
+	if *byClass {
+		fmt.Fprintln(out, "")
+		for _, name := range sortedKeys(classes) {
+			printUsageLine(out, *classes[name], name)
+		}
+	}
+
+	return 0
+}
+
+// groupFor returns the sub-prefix depth levels below prefix that key falls
+// under. Keys that sit less than depth levels deep belong to no group and
+// are only counted in the total, as with du -d.
+func groupFor(key, prefix string, depth int) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, prefix), "/")
+	if len(parts) <= depth {
+		return "", false
+	}
+	return prefix + strings.Join(parts[:depth], "/") + "/", true
+}
+
+func sortedKeys(m map[string]*usage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printUsageLine(w io.Writer, u usage, label string) {
+	fmt.Fprintf(w, "%10s  %8d objects  %s\n", ui.FormatSize(u.bytes), u.objects, label)
+}
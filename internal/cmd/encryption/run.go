@@ -0,0 +1,120 @@
+package encryption
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("encryption", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client encryption [flags] s3://bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show, set, or delete the default encryption of an S3 bucket.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client encryption -show s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client encryption -set AES256 s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client encryption -set aws:kms -kms-key-id alias/my-key s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client encryption -delete s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	set := fs.String("set", "", "Default encryption algorithm to apply (AES256 or aws:kms)")
+	kmsKeyID := fs.String("kms-key-id", "", "KMS key ID, ARN or alias (required with -set aws:kms)")
+	delete := fs.Bool("delete", false, "Delete the default encryption configuration")
+	show := fs.Bool("show", false, "Show the current default encryption")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, err := s3uri.ParseBucket(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !*show && !*delete && *set == "" {
+		fmt.Fprintln(os.Stderr, "Error: Must specify one of -show, -set, or -delete")
+		fs.Usage()
+		return 1
+	}
+
+	if *set != "" {
+		if err := s3ops.ValidateBucketEncryption(*set, *kmsKeyID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if *show {
+		enc, err := s3ops.GetBucketEncryption(ctx, client, bucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if enc == nil {
+			fmt.Println("No default encryption set.")
+			return 0
+		}
+		fmt.Printf("Algorithm:   %s\n", enc.Algorithm)
+		if enc.KMSKeyID != "" {
+			fmt.Printf("KMS key:     %s\n", enc.KMSKeyID)
+		}
+		fmt.Printf("Bucket key:  %t\n", enc.BucketKeyEnabled)
+		return 0
+	}
+
+	if *delete {
+		if err := s3ops.DeleteBucketEncryption(ctx, client, bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Default encryption deleted for bucket %s\n", bucket)
+		return 0
+	}
+
+	if err := s3ops.PutBucketEncryption(ctx, client, bucket, *set, *kmsKeyID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Default encryption %s set for bucket %s\n", *set, bucket)
+	return 0
+}
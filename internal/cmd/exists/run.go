@@ -0,0 +1,99 @@
+package exists
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Exit codes, chosen so the command works in shell conditionals.
+const (
+	exitPresent = 0
+	exitAbsent  = 1
+	exitError   = 2
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("exists", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client exists [flags] s3://bucket[/key]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Check whether an object (or, without a key, a bucket) exists.")
+	fmt.Fprintln(os.Stderr, "Exits 0 if present, 1 if absent and 2 on any other error.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client exists s3://my-bucket/file.txt")
+	fmt.Fprintln(os.Stderr, "  if s3-client exists -quiet s3://my-bucket/done.marker; then ...; fi")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	bucket, key, err := s3uri.ParsePrefix(fs.Arg(0))
+	if err != nil {
+		if !opts.Quiet {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return exitError
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		if !opts.Quiet {
+			fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		}
+		return exitError
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	var found bool
+	if key == "" {
+		found, err = s3ops.BucketExists(ctx, client, bucket)
+	} else {
+		found, err = s3ops.ObjectExists(ctx, client, bucket, key)
+	}
+	if err != nil {
+		if !opts.Quiet {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return exitError
+	}
+
+	if !found {
+		fmt.Fprintf(opts.Out(), "s3://%s/%s does not exist\n", bucket, key)
+		return exitAbsent
+	}
+
+	fmt.Fprintf(opts.Out(), "s3://%s/%s exists\n", bucket, key)
+	return exitPresent
+}
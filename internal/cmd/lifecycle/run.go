@@ -0,0 +1,142 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("lifecycle", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client lifecycle [flags] s3://bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show, set, or delete the lifecycle rules of an S3 bucket.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client lifecycle -show s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client lifecycle -file lifecycle.json s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client lifecycle -delete s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Rule file format (JSON):")
+	fmt.Fprintln(os.Stderr, `  [{"ID":"expire-tmp","Prefix":"tmp/","ExpirationDays":7,"AbortIncompleteMultipartDays":1},`)
+	fmt.Fprintln(os.Stderr, `   {"ID":"archive-logs","Prefix":"logs/","Tags":{"retain":"long"},`)
+	fmt.Fprintln(os.Stderr, `    "Transitions":[{"Days":30,"StorageClass":"STANDARD_IA"},{"Days":365,"StorageClass":"GLACIER"}]}]`)
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "The output of -show can be edited and passed back to -file. Rules using")
+	fmt.Fprintln(os.Stderr, "settings listed under \"Unsupported\" (noncurrent-version actions, dates,")
+	fmt.Fprintln(os.Stderr, "size filters) are refused rather than written back without them.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	file := fs.String("file", "", "Path to lifecycle rules file (JSON)")
+	delete := fs.Bool("delete", false, "Delete lifecycle configuration")
+	show := fs.Bool("show", false, "Show current lifecycle configuration")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, err := s3uri.ParseBucket(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if *show {
+		rules, err := s3ops.GetBucketLifecycle(ctx, client, bucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if rules == nil {
+			fmt.Println("No lifecycle configuration set.")
+			return 0
+		}
+		data, err := json.MarshalIndent(rules, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(data))
+		for _, rule := range rules {
+			if len(rule.Unsupported) > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: rule %q uses settings not shown above; -file will refuse it\n", rule.ID)
+			}
+		}
+		return 0
+	}
+
+	if *delete {
+		if err := s3ops.DeleteBucketLifecycle(ctx, client, bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Lifecycle configuration deleted for bucket %s\n", bucket)
+		return 0
+	}
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "Error: Must specify one of -show, -file, or -delete")
+		fs.Usage()
+		return 1
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading lifecycle file: %v\n", err)
+		return 1
+	}
+	rules, err := s3ops.ParseLifecycleConfig(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing lifecycle file: %v\n", err)
+		return 1
+	}
+	if len(rules) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: lifecycle file contains no rules (use -delete to remove the configuration)")
+		return 1
+	}
+
+	if err := s3ops.PutBucketLifecycle(ctx, client, bucket, rules); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Lifecycle configuration set for bucket %s (%d rules)\n", bucket, len(rules))
+	return 0
+}
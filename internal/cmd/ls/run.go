@@ -0,0 +1,141 @@
+package ls
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/report"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("ls", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client ls [flags] [s3://bucket[/prefix]]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "List buckets, or the directories and objects under a prefix. With -json, each")
+	fmt.Fprintln(os.Stderr, "entry is printed as one JSON object per line (NDJSON).")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client ls")
+	fmt.Fprintln(os.Stderr, "  s3-client ls s3://my-bucket/logs/")
+	fmt.Fprintln(os.Stderr, "  s3-client ls -sort time -reverse s3://my-bucket/backups/")
+	fmt.Fprintln(os.Stderr, "  s3-client ls -recursive -json s3://my-bucket/data/ | jq -r 'select(.size > 1e9) | .key'")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	recursive := fs.Bool("recursive", false, "List every object under the prefix instead of one level")
+	sortFlag := fs.String("sort", "name", "Sort objects by name, size or time (last modified); directories stay first")
+	reverse := fs.Bool("reverse", false, "Reverse the sort order (largest or newest first)")
+
+	listFlags := &config.ListFlags{}
+	config.AddListFlags(fs, listFlags)
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	sortBy, err := s3ops.ParseSortBy(*sortFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := listFlags.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if fs.NArg() < 1 || fs.Arg(0) == "s3://" {
+		return listBuckets(ctx, client)
+	}
+
+	bucket, prefix, err := s3uri.ParsePrefix(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var objects []s3ops.ObjectInfo
+	if *recursive {
+		objects, err = s3ops.ListObjectsByKeyPrefix(ctx, client, bucket, prefix, listFlags.Options()...)
+		if err == nil {
+			s3ops.SortObjects(objects, sortBy, *reverse)
+		}
+	} else {
+		objects, err = s3ops.ListObjectsSorted(ctx, client, bucket, prefix, sortBy, *reverse, listFlags.Options()...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if opts.JSON {
+		if err := report.WriteObjects(os.Stdout, objects); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, obj := range objects {
+		printObject(os.Stdout, obj, *recursive)
+	}
+	return 0
+}
+
+func listBuckets(ctx context.Context, client *s3.Client) int {
+	buckets, err := s3ops.ListBuckets(ctx, client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, b := range buckets {
+		fmt.Printf("%s  %s\n", b.CreationDate.Local().Format("2006-01-02 15:04:05"), b.Name)
+	}
+	return 0
+}
+
+// printObject prints one entry in the style of aws s3 ls: directories as
+// PRE, objects with their modification time and size. Recursive listings
+// show full keys.
+func printObject(w io.Writer, obj s3ops.ObjectInfo, fullKey bool) {
+	name := obj.Name
+	if fullKey {
+		name = obj.Key
+	}
+	if obj.IsDir {
+		fmt.Fprintf(w, "%19s  %10s  %s\n", "", "PRE", name)
+		return
+	}
+	fmt.Fprintf(w, "%s  %10s  %s\n", obj.LastModified.Local().Format("2006-01-02 15:04:05"), ui.FormatSize(obj.Size), name)
+}
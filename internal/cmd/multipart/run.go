@@ -0,0 +1,277 @@
+package multipart
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// newFlagSet names the flag set multipart-<action>, which is also the
+// config file section for that action; the file has no nested sections.
+func newFlagSet(action string) *flag.FlagSet {
+	return flag.NewFlagSet("multipart-"+action, flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client multipart list [flags] s3://bucket[/prefix]")
+	fmt.Fprintln(os.Stderr, "       s3-client multipart abort -upload-id ID [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "       s3-client multipart abort -older-than AGE [-force] [flags] s3://bucket[/prefix]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "List multipart uploads that were started but never completed, with the size of")
+	fmt.Fprintln(os.Stderr, "the parts they hold (billed as storage), or abort them. Without -force,")
+	fmt.Fprintln(os.Stderr, "-older-than only shows what would be aborted.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Config file defaults go in the multipart-list and multipart-abort sections.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client multipart list s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client multipart abort -upload-id 2~abc... s3://my-bucket/backups/big.tar")
+	fmt.Fprintln(os.Stderr, "  s3-client multipart abort -older-than 7d -force s3://my-bucket/backups/")
+	if fs != nil {
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+}
+
+func Run(args []string) int {
+	if len(args) < 1 {
+		printUsage(nil)
+		return 1
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runList(args[1:])
+	case "abort":
+		return runAbort(args[1:])
+	case "-h", "-help", "--help":
+		printUsage(nil)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown action %q (want list or abort)\n\n", args[0])
+		printUsage(nil)
+		return 1
+	}
+}
+
+func runList(args []string) int {
+	fs := newFlagSet("list")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, prefix, err := s3uri.ParsePrefix(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	client, err := newClient(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	uploads, err := s3ops.ListMultipartUploads(ctx, client, bucket, prefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	out := opts.Out()
+	if len(uploads) == 0 {
+		fmt.Fprintf(out, "No incomplete multipart uploads under s3://%s/%s\n", bucket, prefix)
+		return 0
+	}
+
+	var total int64
+	for _, u := range uploads {
+		parts, size, err := s3ops.MultipartUploadSize(ctx, client, bucket, u.Key, u.UploadID)
+		if err != nil {
+			// The upload may have been completed or aborted since it was
+			// listed; show it without a size rather than giving up.
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", u.Key, err)
+			printUpload(out, u, "?", "?")
+			continue
+		}
+		total += size
+		printUpload(out, u, strconv.Itoa(parts), ui.FormatSize(size))
+	}
+	fmt.Fprintf(out, "\n%d incomplete uploads holding %s\n", len(uploads), ui.FormatSize(total))
+	return 0
+}
+
+func runAbort(args []string) int {
+	fs := newFlagSet("abort")
+	uploadID := fs.String("upload-id", "", "Abort this upload of the given key")
+	olderThan := fs.String("older-than", "", "Abort every upload under the prefix started longer ago than this (e.g. 7d, 36h)")
+	force := fs.Bool("force", false, "With -older-than, actually abort the uploads")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if (*uploadID == "") == (*olderThan == "") {
+		fmt.Fprintln(os.Stderr, "Error: specify exactly one of -upload-id or -older-than")
+		return 1
+	}
+	if *force && *olderThan == "" {
+		fmt.Fprintln(os.Stderr, "Error: -force only applies with -older-than")
+		return 1
+	}
+
+	var age time.Duration
+	if *olderThan != "" {
+		var err error
+		age, err = parseAge(*olderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -older-than: %v\n", err)
+			return 1
+		}
+	}
+
+	var bucket, prefix string
+	var err error
+	if *uploadID != "" {
+		bucket, prefix, err = s3uri.Parse(fs.Arg(0))
+	} else {
+		bucket, prefix, err = s3uri.ParsePrefix(fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	client, err := newClient(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	out := opts.Out()
+	if *uploadID != "" {
+		if err := s3ops.AbortMultipartUpload(ctx, client, bucket, prefix, aws.String(*uploadID)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Aborted upload %s of s3://%s/%s\n", *uploadID, bucket, prefix)
+		return 0
+	}
+
+	uploads, err := s3ops.ListMultipartUploads(ctx, client, bucket, prefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cutoff := time.Now().Add(-age)
+	var stale []s3ops.MultipartUpload
+	for _, u := range uploads {
+		if u.Initiated.Before(cutoff) {
+			stale = append(stale, u)
+		}
+	}
+	if len(stale) == 0 {
+		fmt.Fprintf(out, "No incomplete uploads under s3://%s/%s older than %s\n", bucket, prefix, *olderThan)
+		return 0
+	}
+
+	for _, u := range stale {
+		printUpload(out, u, "", "")
+	}
+	fmt.Fprintf(out, "\n%d uploads to abort\n", len(stale))
+
+	if !*force {
+		fmt.Fprintln(os.Stderr, "Nothing aborted; re-run with -force to abort these uploads.")
+		return 0
+	}
+
+	failed := 0
+	for _, u := range stale {
+		if err := s3ops.AbortMultipartUpload(ctx, client, bucket, u.Key, aws.String(u.UploadID)); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed to abort %s (upload %s): %v\n", u.Key, u.UploadID, err)
+		}
+	}
+
+	fmt.Fprintf(out, "Aborted %d uploads\n", len(stale)-failed)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d uploads could not be aborted\n", failed)
+		return 1
+	}
+	return 0
+}
+
+func newClient(ctx context.Context, opts *config.Options) (*s3.Client, error) {
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(cfg), nil
+}
+
+// parseAge accepts Go durations ("36h", "90m") plus a day suffix ("7d").
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q: want e.g. 7d or 36h", s)
+	}
+	return d, nil
+}
+
+// printUpload prints one upload; parts and size are omitted when empty.
+func printUpload(w io.Writer, u s3ops.MultipartUpload, parts, size string) {
+	fmt.Fprintf(w, "%s  ", u.Initiated.Local().Format("2006-01-02 15:04:05"))
+	if parts != "" {
+		fmt.Fprintf(w, "%10s  %5s parts  ", size, parts)
+	}
+	fmt.Fprintf(w, "%s  %s\n", u.UploadID, u.Key)
+}
@@ -0,0 +1,30 @@
+package multipart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"s3-client/internal/shared/config"
+)
+
+func TestConfigSectionPerAction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "multipart-abort:\n  older-than: 7d\nmultipart-list:\n  older-than: 1d\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := newFlagSet("abort")
+	olderThan := fs.String("older-than", "", "")
+	if err := file.Apply(fs); err != nil {
+		t.Fatal(err)
+	}
+	if *olderThan != "7d" {
+		t.Errorf("-older-than = %q from the multipart-abort section, want 7d", *olderThan)
+	}
+}
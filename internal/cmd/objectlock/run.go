@@ -0,0 +1,166 @@
+package objectlock
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("object-lock", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client object-lock [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show or change the Object Lock retention and legal hold of an object. The bucket")
+	fmt.Fprintln(os.Stderr, "must have been created with Object Lock enabled. With no flags, the current")
+	fmt.Fprintln(os.Stderr, "settings are shown.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client object-lock s3://my-bucket/audit/2024.log")
+	fmt.Fprintln(os.Stderr, "  s3-client object-lock -mode COMPLIANCE -retain-until 2031-01-01 s3://my-bucket/audit/2024.log")
+	fmt.Fprintln(os.Stderr, "  s3-client object-lock -legal-hold on s3://my-bucket/evidence.zip")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	mode := fs.String("mode", "", "Retention mode to set: GOVERNANCE or COMPLIANCE (requires -retain-until)")
+	retainUntil := fs.String("retain-until", "", "Retain the object until this date (YYYY-MM-DD or RFC3339)")
+	bypass := fs.Bool("bypass-governance", false, "Allow shortening or replacing GOVERNANCE retention")
+	legalHold := fs.String("legal-hold", "", "Turn the legal hold on or off")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if (*mode == "") != (*retainUntil == "") {
+		fmt.Fprintln(os.Stderr, "Error: -mode and -retain-until must be given together")
+		return 1
+	}
+
+	var until time.Time
+	if *mode != "" {
+		if _, err := s3ops.ParseRetentionMode(*mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		until, err = parseDate(*retainUntil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -retain-until: %v\n", err)
+			return 1
+		}
+		if !until.After(time.Now()) {
+			fmt.Fprintf(os.Stderr, "Error: -retain-until %s is not in the future\n", *retainUntil)
+			return 1
+		}
+	}
+
+	hold := strings.EqualFold(*legalHold, "on")
+	switch strings.ToLower(*legalHold) {
+	case "", "on", "off":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: -legal-hold must be on or off, got %q\n", *legalHold)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+	out := opts.Out()
+
+	if *mode == "" && *legalHold == "" {
+		return show(ctx, client, bucket, key)
+	}
+
+	if *mode != "" {
+		if err := s3ops.PutObjectRetention(ctx, client, bucket, key, *mode, until, *bypass); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Retention %s until %s set on s3://%s/%s\n", strings.ToUpper(*mode), until.Format(time.RFC3339), bucket, key)
+	}
+
+	if *legalHold != "" {
+		if err := s3ops.PutObjectLegalHold(ctx, client, bucket, key, hold); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Legal hold %s for s3://%s/%s\n", strings.ToUpper(*legalHold), bucket, key)
+	}
+
+	return 0
+}
+
+func show(ctx context.Context, client *s3.Client, bucket, key string) int {
+	retention, err := s3ops.GetObjectRetention(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	hold, err := s3ops.GetObjectLegalHold(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if retention == nil {
+		fmt.Println("Retention:   none")
+	} else {
+		fmt.Printf("Retention:   %s until %s\n", retention.Mode, retention.RetainUntil.Format(time.RFC3339))
+	}
+	if hold {
+		fmt.Println("Legal hold:  ON")
+	} else {
+		fmt.Println("Legal hold:  OFF")
+	}
+	return 0
+}
+
+// parseDate accepts a plain date, taken as midnight UTC, or an RFC3339
+// timestamp.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC3339", s)
+	}
+	return t, nil
+}
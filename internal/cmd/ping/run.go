@@ -0,0 +1,150 @@
+package ping
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("ping", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client ping [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Check that the configured endpoint and credentials work by listing buckets.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client ping -profile prod")
+	fmt.Fprintln(os.Stderr, "  s3-client ping -endpoint http://localhost:9000")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	timeout := fs.Duration("timeout", 10*time.Second, "Give up after this long")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Config: %v\n", err)
+		return 1
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "(none)"
+	}
+	out := opts.Out()
+	fmt.Fprintf(out, "Region:    %s\n", region)
+	fmt.Fprintf(out, "Endpoint:  %s\n", endpointURL(ctx, *opts, cfg.Region))
+
+	// A single attempt surfaces the underlying failure instead of a retry
+	// loop ending in a timeout.
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.RetryMaxAttempts = 1
+	})
+
+	start := time.Now()
+	resp, err := client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	elapsed := time.Since(start)
+	if err != nil {
+		category, tip := categorize(ctx, err)
+		fmt.Fprintf(os.Stderr, "✗ %s error after %s: %v\n", category, elapsed.Round(time.Millisecond), err)
+		if tip != "" {
+			fmt.Fprintf(os.Stderr, "Tip: %s\n", tip)
+		}
+		return 1
+	}
+
+	fmt.Fprintf(out, "✓ OK in %s, %d buckets visible\n", elapsed.Round(time.Millisecond), len(resp.Buckets))
+	return 0
+}
+
+// endpointURL reports where requests go: the -endpoint override, or the
+// regional endpoint the SDK resolves for the current settings.
+func endpointURL(ctx context.Context, opts config.Options, region string) string {
+	if opts.Endpoint != "" {
+		return opts.Endpoint
+	}
+	ep, err := s3.NewDefaultEndpointResolverV2().ResolveEndpoint(ctx, s3.EndpointParameters{
+		Region:       aws.String(region),
+		UseFIPS:      aws.Bool(opts.UseFIPS),
+		UseDualStack: aws.Bool(opts.UseDualStack),
+	})
+	if err != nil {
+		return fmt.Sprintf("(unresolved: %v)", err)
+	}
+	return ep.URI.String()
+}
+
+// categorize names the layer a ping failure happened at, with a hint for
+// fixing it.
+func categorize(ctx context.Context, err error) (string, string) {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return "DNS", fmt.Sprintf("host %q does not resolve; check -endpoint or -region.", dnsErr.Name)
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuth x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	switch {
+	case errors.As(err, &recordErr):
+		return "TLS", "the endpoint does not speak TLS; try an http:// -endpoint."
+	case errors.As(err, &certErr), errors.As(err, &unknownAuth), errors.As(err, &hostnameErr):
+		return "TLS", "the endpoint's certificate is not trusted for this host."
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return "Connection", "nothing is listening at the endpoint; is the server running?"
+	}
+	if ctx.Err() != nil {
+		return "Timeout", "no answer in time; check network access to the endpoint or raise -timeout."
+	}
+
+	switch s3ops.ClassifyError(err) {
+	case s3ops.KindAccessDenied:
+		return "Auth", "credentials were rejected or lack s3:ListAllMyBuckets; check -profile and keys."
+	case s3ops.KindWrongRegion:
+		return "Region", "the endpoint expects a different region; try -region."
+	case s3ops.KindThrottled:
+		return "Throttled", "the service is rate limiting; retry shortly."
+	}
+
+	var netErr *net.OpError
+	if errors.As(err, &netErr) {
+		return "Network", ""
+	}
+	return "Request", ""
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("policy", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client policy [flags] s3://bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show, set, or delete the bucket policy of an S3 bucket.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client policy -show s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client policy -file public-read.json s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "  s3-client policy -delete s3://my-bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	file := fs.String("file", "", "Path to bucket policy document (JSON)")
+	delete := fs.Bool("delete", false, "Delete bucket policy")
+	show := fs.Bool("show", false, "Show current bucket policy")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, err := s3uri.ParseBucket(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !*show && !*delete && *file == "" {
+		fmt.Fprintln(os.Stderr, "Error: Must specify one of -show, -file, or -delete")
+		fs.Usage()
+		return 1
+	}
+
+	var policyDoc []byte
+	if !*show && !*delete {
+		policyDoc, err = os.ReadFile(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading policy file: %v\n", err)
+			return 1
+		}
+		var v interface{}
+		if err := json.Unmarshal(policyDoc, &v); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: policy file is not valid JSON: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if *show {
+		doc, err := s3ops.GetBucketPolicy(ctx, client, bucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if doc == "" {
+			fmt.Println("No bucket policy set.")
+			return 0
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, []byte(doc), "", "  "); err != nil {
+			fmt.Println(doc)
+			return 0
+		}
+		fmt.Println(out.String())
+		return 0
+	}
+
+	if *delete {
+		if err := s3ops.DeleteBucketPolicy(ctx, client, bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Bucket policy deleted for bucket %s\n", bucket)
+		return 0
+	}
+
+	if err := s3ops.PutBucketPolicy(ctx, client, bucket, string(policyDoc)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Bucket policy set for bucket %s\n", bucket)
+	return 0
+}
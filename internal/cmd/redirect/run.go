@@ -0,0 +1,82 @@
+package redirect
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("redirect", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client redirect [flags] s3://bucket/key TARGET")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Create an empty object that redirects to TARGET when requested through the")
+	fmt.Fprintln(os.Stderr, "bucket's static website endpoint. TARGET is a path on the same site (/docs/)")
+	fmt.Fprintln(os.Stderr, "or an absolute http(s) URL. Requests through the plain S3 endpoint just get the")
+	fmt.Fprintln(os.Stderr, "empty object; website hosting must be enabled on the bucket.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client redirect s3://my-site/old-page.html /new-page.html")
+	fmt.Fprintln(os.Stderr, "  s3-client redirect s3://my-site/docs https://docs.example.com/")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	target := fs.Arg(1)
+	if err := s3ops.ValidateRedirectLocation(target); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if err := s3ops.PutRedirect(ctx, client, bucket, key, target); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(opts.Out(), "s3://%s/%s now redirects to %s\n", bucket, key, target)
+	return 0
+}
@@ -0,0 +1,128 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("restore", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client restore [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Restore an object from GLACIER or DEEP_ARCHIVE so it can be downloaded,")
+	fmt.Fprintln(os.Stderr, "or show the progress of an earlier restore with -status.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client restore s3://my-bucket/archive.tar")
+	fmt.Fprintln(os.Stderr, "  s3-client restore -days 3 -tier Expedited s3://my-bucket/archive.tar")
+	fmt.Fprintln(os.Stderr, "  s3-client restore -status s3://my-bucket/archive.tar")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	days := fs.Int("days", 7, "Number of days the restored copy stays available")
+	tier := fs.String("tier", "Standard", "Retrieval tier (Standard, Bulk, Expedited)")
+	status := fs.Bool("status", false, "Show restore status instead of starting a restore")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *days < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -days must be at least 1")
+		return 1
+	}
+	if _, err := s3ops.ParseRestoreTier(*tier); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+	out := opts.Out()
+
+	if *status {
+		meta, err := s3ops.HeadObject(ctx, client, bucket, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		printStatus(meta)
+		return 0
+	}
+
+	err = s3ops.RestoreObject(ctx, client, bucket, key, int32(*days), *tier)
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+		fmt.Fprintf(out, "Restore of s3://%s/%s is already in progress\n", bucket, key)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Restore requested for s3://%s/%s (%s tier, %d days)\n", bucket, key, *tier, *days)
+	fmt.Fprintf(out, "Check progress with: s3-client restore -status s3://%s/%s\n", bucket, key)
+	return 0
+}
+
+func printStatus(meta *s3ops.ObjectMetadata) {
+	st := s3ops.ParseRestoreStatus(meta.Restore)
+	storageClass := meta.StorageClass
+	if storageClass == "" {
+		storageClass = "STANDARD"
+	}
+
+	fmt.Printf("Storage class: %s\n", storageClass)
+	switch {
+	case !st.Requested:
+		fmt.Println("Restore:       not requested")
+	case st.Ongoing:
+		fmt.Println("Restore:       in progress")
+	case !st.Expiry.IsZero():
+		fmt.Printf("Restore:       complete, available until %s\n", st.Expiry.Local().Format("2006-01-02 15:04:05"))
+	default:
+		fmt.Println("Restore:       complete")
+	}
+}
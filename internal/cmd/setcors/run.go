@@ -6,9 +6,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"s3-client/internal/s3uri"
 	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/report"
 	"s3-client/internal/shared/s3ops"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -25,6 +27,7 @@ func printUsage(fs *flag.FlagSet) {
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Examples:")
 	fmt.Fprintln(os.Stderr, "  s3-client set-cors s3://my-bucket -cors-file cors.json")
+	fmt.Fprintln(os.Stderr, "  s3-client set-cors s3://my-bucket -cors-file cors.xml")
 	fmt.Fprintln(os.Stderr, "  s3-client set-cors s3://my-bucket -cors-json '[{\"AllowedOrigins\":[\"*\"],\"AllowedMethods\":[\"GET\"]}]'")
 	fmt.Fprintln(os.Stderr, "  s3-client set-cors s3://my-bucket -delete")
 	fmt.Fprintln(os.Stderr, "")
@@ -34,7 +37,7 @@ func printUsage(fs *flag.FlagSet) {
 
 func Run(args []string) int {
 	fs := newFlagSet()
-	corsFile := fs.String("cors-file", "", "Path to CORS configuration file (JSON)")
+	corsFile := fs.String("cors-file", "", "Path to CORS configuration file (JSON or XML)")
 	corsJSON := fs.String("cors-json", "", "CORS configuration as JSON string")
 	delete := fs.Bool("delete", false, "Delete CORS configuration")
 	show := fs.Bool("show", false, "Show current CORS configuration")
@@ -56,7 +59,7 @@ func Run(args []string) int {
 	}
 
 	s3URI := fs.Arg(0)
-	bucket, _, err := s3uri.Parse(s3URI)
+	bucket, err := s3uri.ParseBucket(s3URI)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
@@ -90,13 +93,19 @@ func Run(args []string) int {
 		return 0
 	}
 
+	out := opts.Out()
+	start := time.Now()
+
 	if *delete {
 		err := s3ops.DeleteBucketCors(ctx, client, bucket)
+		if opts.JSON {
+			report.Write(os.Stdout, report.New("set-cors", bucket, start, err))
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			return 1
 		}
-		fmt.Printf("CORS configuration deleted for bucket %s\n", bucket)
+		fmt.Fprintf(out, "CORS configuration deleted for bucket %s\n", bucket)
 		return 0
 	}
 
@@ -126,11 +135,14 @@ func Run(args []string) int {
 	}
 
 	err = s3ops.PutBucketCors(ctx, client, bucket, rules)
+	if opts.JSON {
+		report.Write(os.Stdout, report.New("set-cors", bucket, start, err))
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	fmt.Printf("CORS configuration set for bucket %s\n", bucket)
+	fmt.Fprintf(out, "CORS configuration set for bucket %s\n", bucket)
 	return 0
 }
@@ -0,0 +1,152 @@
+package stat
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("stat", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client stat [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show an object's metadata. With -attributes, also show its stored checksum")
+	fmt.Fprintln(os.Stderr, "and, for multipart objects, the part layout (via GetObjectAttributes).")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client stat s3://my-bucket/file.txt")
+	fmt.Fprintln(os.Stderr, "  s3-client stat -attributes s3://my-bucket/backups/big.tar")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	attributes := fs.Bool("attributes", false, "Also show the checksum and multipart part layout")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	meta, err := s3ops.HeadObject(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	printMetadata(bucket, meta)
+
+	if !*attributes {
+		return 0
+	}
+
+	attrs, err := s3ops.GetObjectAttributes(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	printAttributes(attrs)
+	return 0
+}
+
+func printMetadata(bucket string, meta *s3ops.ObjectMetadata) {
+	fmt.Printf("Object:         s3://%s/%s\n", bucket, meta.Key)
+	fmt.Printf("Size:           %s (%d bytes)\n", ui.FormatSize(meta.Size), meta.Size)
+	fmt.Printf("Last modified:  %s\n", meta.LastModified.Local().Format(time.RFC3339))
+	fmt.Printf("ETag:           %s\n", meta.ETag)
+	printIfSet("Content-Type:   ", meta.ContentType)
+	printIfSet("Cache-Control:  ", meta.CacheControl)
+	printIfSet("Disposition:    ", meta.ContentDisposition)
+	printIfSet("Encoding:       ", meta.ContentEncoding)
+	printIfSet("Storage class:  ", meta.StorageClass)
+	printIfSet("Encryption:     ", meta.ServerSideEncryption)
+	printIfSet("Restore:        ", meta.Restore)
+
+	if len(meta.Metadata) > 0 {
+		keys := make([]string, 0, len(meta.Metadata))
+		for k := range meta.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Println("Metadata:")
+		for _, k := range keys {
+			fmt.Printf("  %s = %s\n", k, meta.Metadata[k])
+		}
+	}
+}
+
+func printAttributes(attrs *s3ops.ObjectAttributes) {
+	fmt.Println()
+	if attrs.Checksum.Algorithm != "" {
+		fmt.Printf("Checksum:       %s %s", attrs.Checksum.Algorithm, attrs.Checksum.Value)
+		if attrs.Checksum.Type != "" {
+			fmt.Printf(" (%s)", attrs.Checksum.Type)
+		}
+		fmt.Println()
+	} else {
+		fmt.Println("Checksum:       none stored")
+	}
+
+	if attrs.TotalParts == 0 {
+		fmt.Println("Parts:          single-part upload")
+		return
+	}
+	fmt.Printf("Parts:          %d\n", attrs.TotalParts)
+	if len(attrs.Parts) == 0 {
+		fmt.Println("  (per-part sizes are only recorded for uploads with additional checksums)")
+		return
+	}
+	for _, p := range attrs.Parts {
+		fmt.Printf("  %5d  %10s", p.PartNumber, ui.FormatSize(p.Size))
+		if p.Checksum.Algorithm != "" {
+			fmt.Printf("  %s %s", p.Checksum.Algorithm, p.Checksum.Value)
+		}
+		fmt.Println()
+	}
+}
+
+func printIfSet(label, value string) {
+	if value != "" {
+		fmt.Printf("%s%s\n", label, value)
+	}
+}
@@ -0,0 +1,129 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("tail", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client tail [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Follow an object that grows over time, like tail -f. The object is polled with")
+	fmt.Fprintln(os.Stderr, "HEAD requests; whenever it gets longer, the new bytes are fetched and written")
+	fmt.Fprintln(os.Stderr, "to stdout. If it gets shorter it is assumed to have been replaced and is")
+	fmt.Fprintln(os.Stderr, "followed from the start. Runs until interrupted.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client tail s3://my-bucket/logs/current.log")
+	fmt.Fprintln(os.Stderr, "  s3-client tail -n 0 -interval 10s s3://my-bucket/logs/current.log | grep ERROR")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	lastBytes := fs.Int64("n", 1024, "Print the last N bytes before following (0 to start at the end)")
+	interval := fs.Duration("interval", 2*time.Second, "How often to check the object for new data")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *lastBytes < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -n must not be negative")
+		return 1
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -interval must be positive")
+		return 1
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	// The first HEAD must succeed; later ones are retried on the next tick.
+	meta, err := s3ops.HeadObject(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	offset := max(meta.Size-*lastBytes, 0)
+	for {
+		if meta != nil && meta.Size < offset {
+			fmt.Fprintln(os.Stderr, "tail: object got shorter; following from the start")
+			offset = 0
+		}
+		if meta != nil && meta.Size > offset {
+			data, err := s3ops.DownloadRange(ctx, client, bucket, key, s3ops.RangeDownload{Start: offset, End: meta.Size - 1})
+			if errors.Is(err, context.Canceled) {
+				return 0
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "tail: %v\n", err)
+			} else {
+				if _, err := os.Stdout.Write(data); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: failed to write: %v\n", err)
+					return 1
+				}
+				offset += int64(len(data))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(*interval):
+		}
+
+		meta, err = s3ops.HeadObject(ctx, client, bucket, key)
+		if errors.Is(err, context.Canceled) {
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tail: %v\n", err)
+		}
+	}
+}
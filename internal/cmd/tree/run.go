@@ -0,0 +1,162 @@
+package tree
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("tree", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client tree [flags] s3://bucket[/prefix]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Print the directories and objects under a prefix as an indented tree.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client tree s3://my-bucket/")
+	fmt.Fprintln(os.Stderr, "  s3-client tree -depth 2 -dirs-only s3://my-bucket/data/")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+// walker lists one level at a time and prints it, recursing into each
+// directory.
+type walker struct {
+	ctx      context.Context
+	client   *s3.Client
+	bucket   string
+	out      io.Writer
+	maxDepth int
+	dirsOnly bool
+	listOpts []s3ops.ListOption
+
+	dirs  int
+	files int
+	bytes int64
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	depth := fs.Int("depth", 0, "Descend at most N levels below the prefix (0 for no limit)")
+	dirsOnly := fs.Bool("dirs-only", false, "List directories only")
+
+	listFlags := &config.ListFlags{}
+	config.AddListFlags(fs, listFlags)
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *depth < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -depth must not be negative")
+		return 1
+	}
+	if err := listFlags.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	bucket, prefix, err := s3uri.ParsePrefix(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	w := &walker{
+		ctx:      ctx,
+		client:   s3.NewFromConfig(cfg),
+		bucket:   bucket,
+		out:      opts.Out(),
+		maxDepth: *depth,
+		dirsOnly: *dirsOnly,
+		listOpts: listFlags.Options(),
+	}
+
+	fmt.Fprintf(w.out, "s3://%s/%s\n", bucket, prefix)
+	if err := w.walk(prefix, "", 1); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if w.dirsOnly {
+		fmt.Fprintf(w.out, "\n%d directories\n", w.dirs)
+	} else {
+		fmt.Fprintf(w.out, "\n%d directories, %d files, %s\n", w.dirs, w.files, ui.FormatSize(w.bytes))
+	}
+	return 0
+}
+
+// walk prints the entries directly under prefix, each line starting with
+// indent, and recurses into directories until maxDepth.
+func (w *walker) walk(prefix, indent string, depth int) error {
+	entries, err := s3ops.ListObjects(w.ctx, w.client, w.bucket, prefix, w.listOpts...)
+	if err != nil {
+		return err
+	}
+
+	if w.dirsOnly {
+		dirs := entries[:0]
+		for _, e := range entries {
+			if e.IsDir {
+				dirs = append(dirs, e)
+			}
+		}
+		entries = dirs
+	}
+
+	for i, e := range entries {
+		last := i == len(entries)-1
+
+		if !e.IsDir {
+			w.files++
+			w.bytes += e.Size
+			ui.TreeEntry(w.out, indent, last, fmt.Sprintf("%s (%s)", e.Name, ui.FormatSize(e.Size)))
+			continue
+		}
+
+		w.dirs++
+		childIndent := ui.TreeEntry(w.out, indent, last, e.Name)
+		if w.maxDepth == 0 || depth < w.maxDepth {
+			if err := w.walk(e.Key, childIndent, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
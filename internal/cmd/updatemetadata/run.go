@@ -0,0 +1,181 @@
+package updatemetadata
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("update-metadata", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client update-metadata [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Change an object's headers or user metadata by copying it onto itself. Anything")
+	fmt.Fprintln(os.Stderr, "not given keeps its current value. The data is unchanged, but the object gets a")
+	fmt.Fprintln(os.Stderr, "new ETag and Last-Modified time.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client update-metadata -content-type application/json s3://my-bucket/data.json")
+	fmt.Fprintln(os.Stderr, "  s3-client update-metadata -cache-control 'max-age=3600' s3://my-site/index.html")
+	fmt.Fprintln(os.Stderr, "  s3-client update-metadata -metadata owner=data-team,stale= s3://my-bucket/report.csv")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	contentType := fs.String("content-type", "", "New Content-Type")
+	cacheControl := fs.String("cache-control", "", "New Cache-Control")
+	contentDisposition := fs.String("content-disposition", "", "New Content-Disposition")
+	metadata := fs.String("metadata", "", "User metadata to set as key=value pairs (comma-separated); key= removes a key")
+	resetACL := fs.Bool("reset-acl", false, "Don't restore the object's ACL after rewriting it; it becomes private")
+	resetTags := fs.Bool("reset-tags", false, "Don't restore the object's tags after rewriting it")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	if *contentType == "" && *cacheControl == "" && *contentDisposition == "" && *metadata == "" {
+		fmt.Fprintln(os.Stderr, "Error: nothing to update; give -content-type, -cache-control, -content-disposition or -metadata")
+		return 1
+	}
+
+	bucket, key, err := s3uri.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	// REPLACE drops whatever is not sent, so start from the current values.
+	current, err := s3ops.HeadObject(ctx, client, bucket, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	copyOpts := s3ops.CopyOptions{
+		MetadataDirective:  types.MetadataDirectiveReplace,
+		Metadata:           maps.Clone(current.Metadata),
+		ContentType:        current.ContentType,
+		CacheControl:       current.CacheControl,
+		ContentDisposition: current.ContentDisposition,
+		ContentEncoding:    current.ContentEncoding,
+		ContentLanguage:    current.ContentLanguage,
+		// A copy without a storage class lands in STANDARD.
+		StorageClass: types.StorageClass(current.StorageClass),
+		PreserveACL:  !*resetACL,
+		PreserveTags: !*resetTags,
+		Warn:         func(err error) { fmt.Fprintf(os.Stderr, "Warning: %v\n", err) },
+	}
+	if copyOpts.Metadata == nil {
+		copyOpts.Metadata = make(map[string]string)
+	}
+	if *contentType != "" {
+		copyOpts.ContentType = *contentType
+	}
+	if *cacheControl != "" {
+		copyOpts.CacheControl = *cacheControl
+	}
+	if *contentDisposition != "" {
+		copyOpts.ContentDisposition = *contentDisposition
+	}
+	for k, v := range parseMetadata(*metadata) {
+		if v == "" {
+			delete(copyOpts.Metadata, k)
+			continue
+		}
+		copyOpts.Metadata[k] = v
+	}
+
+	fmt.Fprintln(os.Stderr, "Note: the object is rewritten in place; its ETag and Last-Modified will change.")
+
+	if err := s3ops.CopyObjectWithOptions(ctx, client, bucket, key, bucket, key, copyOpts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	out := opts.Out()
+	fmt.Fprintf(out, "Updated s3://%s/%s\n", bucket, key)
+	printChange(out, "Content-Type", current.ContentType, copyOpts.ContentType)
+	printChange(out, "Cache-Control", current.CacheControl, copyOpts.CacheControl)
+	printChange(out, "Content-Disposition", current.ContentDisposition, copyOpts.ContentDisposition)
+	keys := make([]string, 0, len(current.Metadata)+len(copyOpts.Metadata))
+	for k := range current.Metadata {
+		keys = append(keys, k)
+	}
+	for k := range copyOpts.Metadata {
+		if _, ok := current.Metadata[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		printChange(out, "x-amz-meta-"+k, current.Metadata[k], copyOpts.Metadata[k])
+	}
+	return 0
+}
+
+func printChange(w io.Writer, name, before, after string) {
+	if before == after {
+		return
+	}
+	if before == "" {
+		before = "(none)"
+	}
+	if after == "" {
+		after = "(none)"
+	}
+	fmt.Fprintf(w, "  %s: %s -> %s\n", name, before, after)
+}
+
+func parseMetadata(s string) map[string]string {
+	meta := make(map[string]string)
+	if s == "" {
+		return meta
+	}
+	pairs := strings.Split(s, ",")
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 {
+			meta[parts[0]] = parts[1]
+		}
+	}
+	return meta
+}
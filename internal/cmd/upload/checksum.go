@@ -0,0 +1,122 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func parseChecksumAlgorithm(s string) (types.ChecksumAlgorithm, error) {
+	if s == "" {
+		return "", nil
+	}
+	alg := types.ChecksumAlgorithm(strings.ToUpper(s))
+	switch alg {
+	case types.ChecksumAlgorithmCrc32, types.ChecksumAlgorithmCrc32c,
+		types.ChecksumAlgorithmSha1, types.ChecksumAlgorithmSha256:
+		return alg, nil
+	}
+	return "", fmt.Errorf("invalid -checksum-algorithm %q: want CRC32, CRC32C, SHA1 or SHA256", s)
+}
+
+func newChecksumHash(alg types.ChecksumAlgorithm) hash.Hash {
+	switch alg {
+	case types.ChecksumAlgorithmCrc32:
+		return crc32.NewIEEE()
+	case types.ChecksumAlgorithmCrc32c:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case types.ChecksumAlgorithmSha1:
+		return sha1.New()
+	default:
+		return sha256.New()
+	}
+}
+
+// computeChecksum returns the base64 checksum S3 expects in the
+// x-amz-checksum-* headers.
+func computeChecksum(alg types.ChecksumAlgorithm, data []byte) string {
+	h := newChecksumHash(alg)
+	h.Write(data)
+	sum := h.Sum(nil)
+	if h32, ok := h.(hash.Hash32); ok {
+		sum = binary.BigEndian.AppendUint32(nil, h32.Sum32())
+	}
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+func setPartChecksum(input *s3.UploadPartInput, alg types.ChecksumAlgorithm, data []byte) {
+	input.ChecksumAlgorithm = alg
+	sum := aws.String(computeChecksum(alg, data))
+	switch alg {
+	case types.ChecksumAlgorithmCrc32:
+		input.ChecksumCRC32 = sum
+	case types.ChecksumAlgorithmCrc32c:
+		input.ChecksumCRC32C = sum
+	case types.ChecksumAlgorithmSha1:
+		input.ChecksumSHA1 = sum
+	case types.ChecksumAlgorithmSha256:
+		input.ChecksumSHA256 = sum
+	}
+}
+
+func completedPart(resp *s3.UploadPartOutput, partNumber int) types.CompletedPart {
+	return types.CompletedPart{
+		ETag:           resp.ETag,
+		PartNumber:     aws.Int32(int32(partNumber)),
+		ChecksumCRC32:  resp.ChecksumCRC32,
+		ChecksumCRC32C: resp.ChecksumCRC32C,
+		ChecksumSHA1:   resp.ChecksumSHA1,
+		ChecksumSHA256: resp.ChecksumSHA256,
+	}
+}
+
+func pickChecksum(alg types.ChecksumAlgorithm, crc32, crc32c, sha1, sha256 *string) string {
+	switch alg {
+	case types.ChecksumAlgorithmCrc32:
+		return aws.ToString(crc32)
+	case types.ChecksumAlgorithmCrc32c:
+		return aws.ToString(crc32c)
+	case types.ChecksumAlgorithmSha1:
+		return aws.ToString(sha1)
+	case types.ChecksumAlgorithmSha256:
+		return aws.ToString(sha256)
+	}
+	return ""
+}
+
+// verifyChecksum re-heads the object with ChecksumMode enabled and compares
+// the stored checksum with the one returned by the upload call.
+func verifyChecksum(ctx context.Context, client *s3.Client, bucket, key string, alg types.ChecksumAlgorithm, expected string, sse *s3ops.SSECustomerKey) error {
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = sse.Fields()
+
+	resp, err := client.HeadObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to verify checksum: %w", err)
+	}
+
+	actual := pickChecksum(alg, resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumSHA1, resp.ChecksumSHA256)
+	if actual == "" {
+		return fmt.Errorf("checksum verification failed: object has no %s checksum", alg)
+	}
+	if expected != "" && actual != expected {
+		return fmt.Errorf("checksum verification failed: stored %s %s, uploaded %s", alg, actual, expected)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"s3-client/internal/shared/s3ops"
+)
+
+const (
+	contentTypeExt   = "ext"
+	contentTypeSniff = "sniff"
+	contentTypeBoth  = "both"
+	contentTypeNone  = "none"
+)
+
+// parseContentTypeMode accepts the -guess-content-type values, keeping the
+// old boolean spellings working.
+func parseContentTypeMode(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case contentTypeExt, contentTypeSniff, contentTypeBoth, contentTypeNone:
+		return strings.ToLower(s), nil
+	case "true":
+		return contentTypeBoth, nil
+	case "false":
+		return contentTypeNone, nil
+	}
+	return "", fmt.Errorf("invalid -guess-content-type %q: want ext, sniff, both or none", s)
+}
+
+// contentTypeModeFlag is -guess-content-type. It is a boolean flag to the
+// flag package, so a bare -guess-content-type still means "both" and does
+// not swallow the next argument; other modes need -guess-content-type=mode.
+type contentTypeModeFlag string
+
+func (m *contentTypeModeFlag) String() string { return string(*m) }
+
+func (m *contentTypeModeFlag) Set(s string) error {
+	mode, err := parseContentTypeMode(s)
+	if err != nil {
+		return err
+	}
+	*m = contentTypeModeFlag(mode)
+	return nil
+}
+
+func (m *contentTypeModeFlag) IsBoolFlag() bool { return true }
+
+// detectContentType picks a Content-Type for file according to mode, with
+// overrides taking precedence over the built-in extension table. Sniffing
+// uses ReadAt, so the file offset is untouched and the upload still sends the
+// whole file.
+func detectContentType(file *os.File, localPath, mode string, overrides map[string]string) string {
+	switch mode {
+	case contentTypeNone:
+		return ""
+	case contentTypeSniff:
+		return sniffContentType(file)
+	case contentTypeBoth:
+		if ct := s3ops.ContentTypeByExt(localPath, overrides); ct != "" {
+			return ct
+		}
+		return sniffContentType(file)
+	default:
+		if ct := s3ops.ContentTypeByExt(localPath, overrides); ct != "" {
+			return ct
+		}
+		return "application/octet-stream"
+	}
+}
+
+func sniffContentType(file *os.File) string {
+	buf := make([]byte, 512)
+	n, _ := file.ReadAt(buf, 0)
+	return http.DetectContentType(buf[:n])
+}
+
+// parseContentTypeMap parses -content-type-map ("ext=type,ext=type") into
+// the overrides s3ops.ContentTypeByExt expects. Extensions may be given
+// with or without the leading dot.
+func parseContentTypeMap(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		ext, contentType, ok := strings.Cut(pair, "=")
+		ext, contentType = strings.TrimSpace(ext), strings.TrimSpace(contentType)
+		if !ok || ext == "" || ext == "." || contentType == "" {
+			return nil, fmt.Errorf("invalid -content-type-map entry %q: want ext=type", pair)
+		}
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		overrides[ext] = contentType
+	}
+	return overrides, nil
+}
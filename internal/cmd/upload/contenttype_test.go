@@ -0,0 +1,124 @@
+package upload
+
+import (
+	"flag"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestContentTypeModeFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantMode string
+		wantArgs []string
+	}{
+		{[]string{"file.txt", "bucket/key"}, contentTypeBoth, []string{"file.txt", "bucket/key"}},
+		{[]string{"-guess-content-type", "file.txt", "bucket/key"}, contentTypeBoth, []string{"file.txt", "bucket/key"}},
+		{[]string{"-guess-content-type=ext", "file.txt", "bucket/key"}, contentTypeExt, []string{"file.txt", "bucket/key"}},
+		{[]string{"-guess-content-type=false", "file.txt", "bucket/key"}, contentTypeNone, []string{"file.txt", "bucket/key"}},
+		{[]string{"-guess-content-type=SNIFF", "file.txt", "bucket/key"}, contentTypeSniff, []string{"file.txt", "bucket/key"}},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+		mode := contentTypeModeFlag(contentTypeBoth)
+		fs.Var(&mode, "guess-content-type", "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Errorf("Parse(%q): %v", tt.args, err)
+			continue
+		}
+		if string(mode) != tt.wantMode || !slices.Equal(fs.Args(), tt.wantArgs) {
+			t.Errorf("Parse(%q): mode %q, args %q; want %q, %q", tt.args, mode, fs.Args(), tt.wantMode, tt.wantArgs)
+		}
+	}
+
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	mode := contentTypeModeFlag(contentTypeBoth)
+	fs.Var(&mode, "guess-content-type", "")
+	if err := fs.Parse([]string{"-guess-content-type=magic", "file.txt", "bucket/key"}); err == nil {
+		t.Error("Parse accepted -guess-content-type=magic")
+	}
+}
+
+func TestParseContentTypeMap(t *testing.T) {
+	got, err := parseContentTypeMap("log=text/plain, .JSON = application/vnd.api+json,md=text/x-markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		".log":  "text/plain",
+		".json": "application/vnd.api+json",
+		".md":   "text/x-markdown",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("parseContentTypeMap() = %v, want %v", got, want)
+	}
+
+	if got, err := parseContentTypeMap(""); got != nil || err != nil {
+		t.Errorf(`parseContentTypeMap("") = %v, %v; want nil, nil`, got, err)
+	}
+	for _, s := range []string{"log", "=text/plain", "log=", ".=text/plain", "log=text/plain,"} {
+		if _, err := parseContentTypeMap(s); err == nil {
+			t.Errorf("parseContentTypeMap(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestDetectContentTypePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) *os.File {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+	jsonFile := write("data.json", `{"a": 1}`)
+	htmlNoExt := write("page", "<!DOCTYPE html><html><body>hi</body></html>")
+	logFile := write("server.log", "plain text")
+
+	overrides := map[string]string{".json": "application/vnd.api+json"}
+	tests := []struct {
+		name      string
+		file      *os.File
+		mode      string
+		overrides map[string]string
+		want      string
+	}{
+		{"ext uses table", jsonFile, contentTypeExt, nil, "application/json"},
+		{"ext override wins", jsonFile, contentTypeExt, overrides, "application/vnd.api+json"},
+		{"ext unknown falls back to octet-stream", logFile, contentTypeExt, nil, "application/octet-stream"},
+		{"both override wins over sniffing", jsonFile, contentTypeBoth, overrides, "application/vnd.api+json"},
+		{"both sniffs unknown extension", htmlNoExt, contentTypeBoth, nil, "text/html; charset=utf-8"},
+		{"sniff ignores overrides", jsonFile, contentTypeSniff, overrides, "text/plain; charset=utf-8"},
+		{"none", jsonFile, contentTypeNone, overrides, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectContentType(tt.file, tt.file.Name(), tt.mode, tt.overrides); got != tt.want {
+				t.Errorf("detectContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldGzipUsesOverrides(t *testing.T) {
+	uo := uploadOptions{gzip: true}
+	if uo.shouldGzip("photo.jpg") {
+		t.Error("shouldGzip(photo.jpg) = true, want false for an already compressed type")
+	}
+	uo.contentTypeMap = map[string]string{".jpg": "text/plain"}
+	if !uo.shouldGzip("photo.jpg") {
+		t.Error("shouldGzip(photo.jpg) with .jpg mapped to text/plain = false, want true")
+	}
+}
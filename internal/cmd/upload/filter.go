@@ -0,0 +1,119 @@
+package upload
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// patternList is a repeatable flag collecting glob patterns.
+type patternList []string
+
+func (p *patternList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternList) Set(s string) error {
+	*p = append(*p, s)
+	return nil
+}
+
+// Reset drops the collected patterns, so that patterns given on the command
+// line replace those from the config file.
+func (p *patternList) Reset() {
+	*p = nil
+}
+
+// fileFilter decides which files of a directory upload are sent. Patterns
+// use path.Match syntax and are tried against both the slash-separated path
+// relative to the uploaded directory and the file's base name, so "*.log"
+// matches at any depth and "build/*" only directly under build/.
+type fileFilter struct {
+	include []string
+	exclude []string
+}
+
+// newFileFilter merges the inline -include/-exclude patterns with those read
+// from the -include-from/-exclude-from files. It returns nil, meaning every
+// file is uploaded, when no patterns are given.
+func newFileFilter(include, exclude patternList, includeFrom, excludeFrom string) (*fileFilter, error) {
+	f := &fileFilter{include: include, exclude: exclude}
+	if includeFrom != "" {
+		patterns, err := readPatternFile(includeFrom)
+		if err != nil {
+			return nil, fmt.Errorf("-include-from: %w", err)
+		}
+		f.include = append(f.include, patterns...)
+	}
+	if excludeFrom != "" {
+		patterns, err := readPatternFile(excludeFrom)
+		if err != nil {
+			return nil, fmt.Errorf("-exclude-from: %w", err)
+		}
+		f.exclude = append(f.exclude, patterns...)
+	}
+
+	for _, p := range slices.Concat(f.include, f.exclude) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// readPatternFile reads one glob per line, ignoring blank lines and lines
+// starting with #, like .gitignore or rsync's --exclude-from.
+func readPatternFile(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// allows reports whether the file at rel (slash-separated, relative to the
+// uploaded directory) should be uploaded: it must match an include pattern
+// when there are any, and no exclude pattern.
+func (f *fileFilter) allows(rel string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchAny(f.include, rel) {
+		return false
+	}
+	return !matchAny(f.exclude, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	base := path.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package upload
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadPatternFileSkipsBlankLinesAndComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns")
+	content := "# build output\n*.o\n\n   \n  # indented comment\n  build/*  \n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readPatternFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"*.o", "build/*"}; !slices.Equal(got, want) {
+		t.Errorf("readPatternFile() = %q, want %q", got, want)
+	}
+}
+
+func TestFileFilterAllows(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude patternList
+		allowed          []string
+		denied           []string
+	}{
+		{
+			name:    "exclude by base name at any depth",
+			exclude: patternList{"*.log"},
+			allowed: []string{"main.go", "logs/readme.txt"},
+			denied:  []string{"app.log", "var/log/app.log"},
+		},
+		{
+			name:    "exclude by relative path",
+			exclude: patternList{"build/*"},
+			allowed: []string{"src/build/out.o", "build"},
+			denied:  []string{"build/out.o"},
+		},
+		{
+			name:    "include restricts to matches",
+			include: patternList{"*.go", "docs/*"},
+			allowed: []string{"main.go", "cmd/tool/main.go", "docs/index.md"},
+			denied:  []string{"README.md", "docs/img/logo.png"},
+		},
+		{
+			name:    "exclude wins over include",
+			include: patternList{"*.go"},
+			exclude: patternList{"*_test.go"},
+			allowed: []string{"main.go"},
+			denied:  []string{"main_test.go", "README.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newFileFilter(tt.include, tt.exclude, "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, rel := range tt.allowed {
+				if !f.allows(rel) {
+					t.Errorf("allows(%q) = false, want true", rel)
+				}
+			}
+			for _, rel := range tt.denied {
+				if f.allows(rel) {
+					t.Errorf("allows(%q) = true, want false", rel)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFileFilter(t *testing.T) {
+	f, err := newFileFilter(nil, nil, "", "")
+	if err != nil || f != nil {
+		t.Fatalf("newFileFilter() without patterns = %v, %v; want nil, nil", f, err)
+	}
+	if !f.allows("anything") {
+		t.Error("nil filter rejected a file")
+	}
+
+	if _, err := newFileFilter(patternList{"[unclosed"}, nil, "", ""); err == nil {
+		t.Error("newFileFilter() accepted an invalid pattern")
+	}
+	if _, err := newFileFilter(nil, nil, "", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("newFileFilter() accepted a missing -exclude-from file")
+	}
+
+	path := filepath.Join(t.TempDir(), "exclude")
+	if err := os.WriteFile(path, []byte("# comment\n*.tmp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err = newFileFilter(nil, patternList{"*.bak"}, "", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"*.bak", "*.tmp"}; !slices.Equal(f.exclude, want) {
+		t.Errorf("exclude = %q, want %q", f.exclude, want)
+	}
+}
@@ -0,0 +1,68 @@
+package upload
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"s3-client/internal/shared/s3ops"
+)
+
+func (uo uploadOptions) shouldGzip(localPath string) bool {
+	if !uo.gzip {
+		return false
+	}
+	return uo.forceGzip || !isCompressedType(s3ops.ContentTypeByExt(localPath, uo.contentTypeMap))
+}
+
+// isCompressedType reports content types that gain nothing from gzip.
+func isCompressedType(contentType string) bool {
+	switch contentType {
+	case "application/zip", "application/gzip", "image/jpeg", "image/png", "image/gif",
+		"image/webp", "image/avif", "font/woff2", "video/mp4", "video/webm", "audio/mpeg":
+		return true
+	}
+	return false
+}
+
+func gzipFile(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(gw, r); err != nil {
+		return nil, fmt.Errorf("failed to gzip file: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to gzip file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gzipStream compresses r on the fly. Closing the returned reader stops the
+// compressing goroutine if the consumer gives up early.
+func gzipStream(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		gw := gzip.NewWriter(pw)
+		_, err := io.Copy(gw, r)
+		if err == nil {
+			err = gw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// countingReader tracks bytes read from the source file; it is read from
+// another goroutine when the gzip pipe is in use.
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,80 @@
+package upload
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// manifestEntry records one uploaded object for later verification.
+type manifestEntry struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+	ETag string `json:"etag"`
+}
+
+// manifest collects an entry per uploaded object and writes them as CSV or
+// JSON, chosen by the extension of path. A nil *manifest ignores every call,
+// so callers need not check whether -manifest was given.
+type manifest struct {
+	path    string
+	entries []manifestEntry
+}
+
+func newManifest(path string) (*manifest, error) {
+	if path == "" {
+		return nil, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".json":
+		return &manifest{path: path}, nil
+	}
+	return nil, fmt.Errorf("manifest %q must end in .csv or .json", path)
+}
+
+func (m *manifest) add(key string, size int64, etag string) {
+	if m == nil {
+		return
+	}
+	m.entries = append(m.entries, manifestEntry{Key: key, Size: size, ETag: strings.Trim(etag, `"`)})
+}
+
+func (m *manifest) write() error {
+	if m == nil {
+		return nil
+	}
+
+	f, err := os.Create(m.path)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(m.path)) == ".json" {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		entries := m.entries
+		if entries == nil {
+			entries = []manifestEntry{}
+		}
+		if err := enc.Encode(entries); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		return f.Close()
+	}
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"key", "size", "etag"})
+	for _, e := range m.entries {
+		w.Write([]string{e.Key, strconv.FormatInt(e.Size, 10), e.ETag})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return f.Close()
+}
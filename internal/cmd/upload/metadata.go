@@ -0,0 +1,108 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// loadMetadata combines -metadata-file and -metadata; keys given on the
+// command line win over the file's.
+func loadMetadata(path, flagValue string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if path != "" {
+		fromFile, err := parseMetadataFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fromFile {
+			meta[k] = v
+		}
+	}
+
+	fromFlag, err := parseMetadata(flagValue)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range fromFlag {
+		meta[k] = v
+	}
+
+	if len(meta) == 0 {
+		return nil, nil
+	}
+	return meta, nil
+}
+
+// parseMetadata parses -metadata ("KEY=VALUE,KEY=VALUE"). Values may contain
+// "=" but not ","; use -metadata-file for those.
+func parseMetadata(s string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if s == "" {
+		return meta, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid -metadata entry %q: want KEY=VALUE", pair)
+		}
+		meta[k] = v
+	}
+	return meta, nil
+}
+
+// parseMetadataFile reads a JSON object of string values, or, if the file
+// doesn't start with "{", .env-style lines: KEY=VALUE, optionally prefixed
+// with "export" and with the value in double or single quotes. Blank lines
+// and # comments are skipped.
+func parseMetadataFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read -metadata-file: %w", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var meta map[string]string
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("%s: metadata JSON must be an object of string values: %w", path, err)
+		}
+		return meta, nil
+	}
+
+	meta := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		v = strings.TrimSpace(v)
+		switch {
+		case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+			unquoted, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			v = unquoted
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			v = v[1 : len(v)-1]
+		}
+		meta[k] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read -metadata-file: %w", err)
+	}
+	return meta, nil
+}
@@ -0,0 +1,98 @@
+package upload
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]string
+	}{
+		{"", map[string]string{}},
+		{"owner=ops", map[string]string{"owner": "ops"}},
+		{" owner = ops ,team=data", map[string]string{"owner": " ops ", "team": "data"}},
+		{"query=a=1&b=2", map[string]string{"query": "a=1&b=2"}},
+		{"empty=", map[string]string{"empty": ""}},
+	}
+	for _, tt := range tests {
+		got, err := parseMetadata(tt.in)
+		if err != nil {
+			t.Errorf("parseMetadata(%q) = %v", tt.in, err)
+			continue
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("parseMetadata(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// A comma always separates entries, so a value containing one leaves an
+	// entry without "=".
+	for _, in := range []string{"note=a,b", "owner", "=value", "owner=ops,"} {
+		if _, err := parseMetadata(in); err == nil {
+			t.Errorf("parseMetadata(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func writeMetadataFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "meta")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseMetadataFile(t *testing.T) {
+	want := map[string]string{
+		"note":  "a, b, and c",
+		"query": "x=1,y=2",
+		"owner": "ops",
+	}
+
+	envFile := writeMetadataFile(t, `
+# comment
+note="a, b, and c"
+export query='x=1,y=2'
+owner = ops
+`)
+	jsonFile := writeMetadataFile(t, `{"note": "a, b, and c", "query": "x=1,y=2", "owner": "ops"}`)
+
+	for name, path := range map[string]string{"env": envFile, "json": jsonFile} {
+		got, err := parseMetadataFile(path)
+		if err != nil {
+			t.Errorf("%s: parseMetadataFile() = %v", name, err)
+			continue
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf("%s: parseMetadataFile() = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, content := range []string{"no equals sign", `{"n": 1}`, `bad="unterminated\"`} {
+		if _, err := parseMetadataFile(writeMetadataFile(t, content)); err == nil {
+			t.Errorf("parseMetadataFile(%q) succeeded, want an error", content)
+		}
+	}
+}
+
+func TestLoadMetadataFlagOverridesFile(t *testing.T) {
+	path := writeMetadataFile(t, "owner=file\nnote=\"from, the file\"\n")
+
+	got, err := loadMetadata(path, "owner=flag,team=data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"owner": "flag", "note": "from, the file", "team": "data"}
+	if !maps.Equal(got, want) {
+		t.Errorf("loadMetadata() = %q, want %q", got, want)
+	}
+
+	if got, err := loadMetadata("", ""); got != nil || err != nil {
+		t.Errorf(`loadMetadata("", "") = %v, %v; want nil, nil`, got, err)
+	}
+}
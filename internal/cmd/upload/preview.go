@@ -0,0 +1,93 @@
+package upload
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"s3-client/internal/shared/ui"
+)
+
+// previewDir is one directory of the -tree preview.
+type previewDir struct {
+	dirs  map[string]*previewDir
+	files map[string]int64
+}
+
+func newPreviewDir() *previewDir {
+	return &previewDir{dirs: map[string]*previewDir{}, files: map[string]int64{}}
+}
+
+// printTree renders files, whose keys all start with prefix, as an indented
+// tree below a header line, the same way the tree command draws a bucket.
+func printTree(w io.Writer, header, prefix string, files []localFile) {
+	root := newPreviewDir()
+	var total int64
+	for _, f := range files {
+		total += f.size
+		d := root
+		parts := strings.Split(strings.TrimPrefix(f.key, prefix), "/")
+		for _, name := range parts[:len(parts)-1] {
+			child, ok := d.dirs[name]
+			if !ok {
+				child = newPreviewDir()
+				d.dirs[name] = child
+			}
+			d = child
+		}
+		d.files[parts[len(parts)-1]] = f.size
+	}
+
+	fmt.Fprintln(w, header)
+	dirs := root.print(w, "")
+	fmt.Fprintf(w, "\n%d directories, %d files, %s\n", dirs, len(files), ui.FormatSize(total))
+}
+
+// print writes the entries of d in name order and returns how many
+// directories it printed.
+func (d *previewDir) print(w io.Writer, indent string) int {
+	names := make([]string, 0, len(d.dirs)+len(d.files))
+	for name := range d.dirs {
+		names = append(names, name)
+	}
+	for name := range d.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	count := 0
+	for i, name := range names {
+		last := i == len(names)-1
+		if child, ok := d.dirs[name]; ok {
+			count++
+			count += child.print(w, ui.TreeEntry(w, indent, last, name+"/"))
+			continue
+		}
+		ui.TreeEntry(w, indent, last, fmt.Sprintf("%s (%s)", name, ui.FormatSize(d.files[name])))
+	}
+	return count
+}
+
+// confirmUpload asks whether to go ahead after the -tree preview. Without a
+// terminal on stdin, or with -quiet, nobody is there to answer and the
+// upload proceeds.
+func confirmUpload(quiet bool) bool {
+	if quiet || !stdinIsTerminal() {
+		return true
+	}
+	fmt.Fprint(os.Stderr, "Upload these files? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
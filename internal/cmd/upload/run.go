@@ -1,21 +1,29 @@
 package upload
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"s3-client/internal/s3uri"
 	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/metrics"
+	"s3-client/internal/shared/ratelimit"
+	"s3-client/internal/shared/report"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/time/rate"
 )
 
 func newFlagSet() *flag.FlagSet {
@@ -31,6 +39,13 @@ func printUsage(fs *flag.FlagSet) {
 	fmt.Fprintln(os.Stderr, "  s3-client upload file.txt s3://my-bucket/backups/")
 	fmt.Fprintln(os.Stderr, "  s3-client upload -profile prod -region us-west-2 ./data/ s3://my-bucket/data/")
 	fmt.Fprintln(os.Stderr, "  s3-client upload -multipart -part-size 25 large.file s3://my-bucket/large/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -gzip ./site/ s3://my-bucket/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -cache-control 'public, max-age=31536000' ./site/assets/ s3://my-site/assets/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -dry-run ./backups/ s3://my-bucket/nightly/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -continue-on-error -retries 3 ./backups/ s3://my-bucket/nightly/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -exclude '*.tmp' -exclude-from .s3ignore ./site/ s3://my-site/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -tree -exclude-from .s3ignore ./site/ s3://my-site/")
+	fmt.Fprintln(os.Stderr, "  s3-client upload -skip-existing -checksum ./backups/ s3://my-bucket/nightly/")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Flags:")
 	fs.PrintDefaults()
@@ -41,7 +56,34 @@ func Run(args []string) int {
 	multipart := fs.Bool("multipart", false, "Use multipart upload for large files")
 	partSizeMB := fs.Int("part-size", 10, "Part size in MB for multipart upload")
 	metadata := fs.String("metadata", "", "Metadata in KEY=VALUE,KEY=VALUE format")
-	guessContentType := fs.Bool("guess-content-type", true, "Guess content type from file extension")
+	metadataFile := fs.String("metadata-file", "", "Read metadata from a JSON object or .env-style KEY=VALUE file; -metadata entries override it")
+	guessContentType := contentTypeModeFlag(contentTypeBoth)
+	fs.Var(&guessContentType, "guess-content-type", "How to pick Content-Type, as -guess-content-type=mode: ext (extension), sniff (file bytes), both (extension, then bytes), or none")
+	contentTypeMapFlag := fs.String("content-type-map", "", "Extra or overriding extension mappings as ext=type pairs (comma-separated), e.g. webmanifest=application/manifest+json")
+	contentEncoding := fs.String("content-encoding", "", "Content-Encoding header to set on uploaded objects")
+	cacheControl := fs.String("cache-control", "", "Cache-Control header to set on uploaded objects (e.g. 'public, max-age=31536000')")
+	contentDisposition := fs.String("content-disposition", "", "Content-Disposition header to set on uploaded objects (e.g. attachment)")
+	gzipFlag := fs.Bool("gzip", false, "Gzip files before upload and set Content-Encoding: gzip (skips already-compressed types)")
+	forceGzip := fs.Bool("force-gzip", false, "With -gzip, also compress already-compressed types (images, archives)")
+	aclFlag := fs.String("acl", "", "Canned ACL for uploaded objects (private, public-read, public-read-write, authenticated-read, bucket-owner-full-control)")
+	sseKey := fs.String("sse-c-key", "", "Encrypt with SSE-C: path to a key file or a base64-encoded 32-byte key")
+	maxRate := fs.String("max-rate", "", "Cap upload bandwidth (e.g. 500KB, 10MB, 1G per second)")
+	logFile := fs.String("log-file", "", "Append a JSON-lines record per uploaded object to this file")
+	checksumAlgorithm := fs.String("checksum-algorithm", "", "Additional checksum to compute and verify: CRC32, CRC32C, SHA1 or SHA256")
+	continueOnError := fs.Bool("continue-on-error", false, "For directories, keep uploading after a file fails and report all failures at the end")
+	retries := fs.Int("retries", 0, "Retry each failed file this many times, with backoff, before giving up on it")
+	manifestPath := fs.String("manifest", "", "Write each uploaded key, size and ETag to this file (.csv or .json)")
+	literalKeys := fs.Bool("literal-keys", false, "Use the destination key exactly as given, without normalizing path separators, // or . and .. segments")
+	dryRun := fs.Bool("dry-run", false, "Print the keys and sizes that would be uploaded without uploading anything")
+	var include, exclude patternList
+	fs.Var(&include, "include", "For directories, only upload files matching this glob (repeatable)")
+	fs.Var(&exclude, "exclude", "For directories, skip files matching this glob (repeatable)")
+	includeFrom := fs.String("include-from", "", "Read -include patterns from this file, one per line (# comments allowed)")
+	excludeFrom := fs.String("exclude-from", "", "Read -exclude patterns from this file, one per line (# comments allowed)")
+	treePreview := fs.Bool("tree", false, "Show the files to upload as a tree with sizes and ask before starting")
+	yes := fs.Bool("yes", false, "With -tree, start without asking")
+	skipExisting := fs.Bool("skip-existing", false, "Skip files whose destination object already exists with the same size")
+	checksum := fs.Bool("checksum", false, "With -skip-existing, also require the object's ETag to match the local file's MD5")
 
 	opts := &config.Options{}
 	config.AddFlags(fs, opts)
@@ -62,11 +104,98 @@ func Run(args []string) int {
 	localPath := fs.Arg(0)
 	s3URI := fs.Arg(1)
 
+	contentTypeMap, err := parseContentTypeMap(*contentTypeMapFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	meta, err := loadMetadata(*metadataFile, *metadata)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var acl types.ObjectCannedACL
+	if *aclFlag != "" {
+		acl, err = s3ops.ParseCannedACL(*aclFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	var sse *s3ops.SSECustomerKey
+	if *sseKey != "" {
+		sse, err = s3ops.ParseSSECustomerKey(*sseKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	var limiter *rate.Limiter
+	if *maxRate != "" {
+		bytesPerSec, err := ratelimit.ParseRate(*maxRate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -max-rate: %v\n", err)
+			return 1
+		}
+		limiter = ratelimit.New(bytesPerSec)
+	}
+
+	checksumAlg, err := parseChecksumAlgorithm(*checksumAlgorithm)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	filter, err := newFileFilter(include, exclude, *includeFrom, *excludeFrom)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	uploadManifest, err := newManifest(*manifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *retries < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -retries must not be negative")
+		return 1
+	}
+
+	var skip *skipOptions
+	if *skipExisting {
+		if *gzipFlag {
+			fmt.Fprintln(os.Stderr, "Error: -skip-existing cannot be combined with -gzip; compressed objects can't be compared with local files")
+			return 1
+		}
+		if *checksum && sse != nil {
+			fmt.Fprintln(os.Stderr, "Error: -checksum cannot be combined with -sse-c-key; ETags of SSE-C objects are not MD5s")
+			return 1
+		}
+		skip = &skipOptions{checksum: *checksum, sse: sse}
+	} else if *checksum {
+		fmt.Fprintln(os.Stderr, "Error: -checksum requires -skip-existing")
+		return 1
+	}
+
+	if *gzipFlag && *contentEncoding != "" && *contentEncoding != "gzip" {
+		fmt.Fprintf(os.Stderr, "Error: -gzip conflicts with -content-encoding %q\n", *contentEncoding)
+		return 1
+	}
+
 	bucket, keyPrefix, err := s3uri.Parse(s3URI)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
+	if !*literalKeys {
+		keyPrefix = s3uri.NormalizeKey(keyPrefix)
+	}
 
 	stat, err := os.Stat(localPath)
 	if err != nil {
@@ -74,7 +203,30 @@ func Run(args []string) int {
 		return 1
 	}
 
-	ctx := context.Background()
+	if *treePreview {
+		prefix := keyPrefix
+		files := []localFile{{path: localPath, key: keyPrefix + filepath.Base(localPath), size: stat.Size()}}
+		if stat.IsDir() {
+			dir := strings.TrimSuffix(localPath, string(os.PathSeparator))
+			prefix = keyPrefix + filepath.Base(dir) + "/"
+			files, err = collectFiles(dir, prefix, filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+		}
+		printTree(opts.Out(), fmt.Sprintf("s3://%s/%s", bucket, prefix), prefix, files)
+		if !*yes && !*dryRun && !confirmUpload(opts.Quiet) {
+			fmt.Fprintln(os.Stderr, "Upload cancelled.")
+			return 1
+		}
+	}
+
+	// Ctrl+C cancels ctx so in-flight requests stop and any multipart upload
+	// is aborted instead of being left behind.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	cfg, err := config.Load(ctx, *opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
@@ -90,100 +242,312 @@ func Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "\nDetail: %v\n", err)
 		return 1
 	}
+	out := opts.Out()
 	if opts.Profile != "" {
-		fmt.Printf("Using AWS profile: %s (source: %s)\n", opts.Profile, creds.Source)
+		fmt.Fprintf(out, "Using AWS profile: %s (source: %s)\n", opts.Profile, creds.Source)
 	}
 
 	client := s3.NewFromConfig(cfg)
 
-	var meta map[string]string
-	if *metadata != "" {
-		meta = parseMetadata(*metadata)
+	if *dryRun {
+		return runDryRun(ctx, client, *opts, localPath, stat, bucket, keyPrefix, *multipart, int64(*partSizeMB)*1024*1024, filter, skip)
+	}
+
+	transferLog := metrics.Open(*logFile)
+	defer transferLog.Close()
+
+	uo := uploadOptions{
+		contentTypeMode: string(guessContentType),
+		contentTypeMap:  contentTypeMap,
+		contentEncoding: *contentEncoding,
+		cacheControl:    *cacheControl,
+		contentDisp:     *contentDisposition,
+		gzip:            *gzipFlag,
+		forceGzip:       *forceGzip,
+		acl:             acl,
+		checksumAlg:     checksumAlg,
+		sse:             sse,
+		log:             transferLog,
+		limiter:         limiter,
+		continueOnError: *continueOnError,
+		retries:         *retries,
+		manifest:        uploadManifest,
+		meta:            meta,
+		filter:          filter,
+		skip:            skip,
+		out:             out,
 	}
 
 	start := time.Now()
+	var keys []string
+	var bytes int64
 
 	if stat.IsDir() {
 		localPath = strings.TrimSuffix(localPath, string(os.PathSeparator))
 		dirName := filepath.Base(localPath)
 		prefix := keyPrefix + dirName + "/"
 
-		fmt.Printf("Uploading directory: %s\n", localPath)
-		fmt.Printf("To: s3://%s/%s\n\n", bucket, prefix)
+		fmt.Fprintf(out, "Uploading directory: %s\n", localPath)
+		fmt.Fprintf(out, "To: s3://%s/%s\n\n", bucket, prefix)
 
-		err = uploadDirectory(ctx, client, localPath, bucket, prefix, meta, *guessContentType)
+		keys, bytes, err = uploadDirectory(ctx, client, localPath, bucket, prefix, uo)
 	} else {
 		fileName := filepath.Base(localPath)
 		key := keyPrefix + fileName
 
-		fmt.Printf("Uploading file: %s\n", localPath)
-		fmt.Printf("To: s3://%s/%s\n\n", bucket, key)
-
-		if *multipart || stat.Size() > int64(*partSizeMB)*1024*1024 {
-			err = uploadMultipart(ctx, client, localPath, bucket, key, int64(*partSizeMB)*1024*1024, meta)
-		} else {
-			err = uploadSingleFile(ctx, client, localPath, bucket, key, meta, *guessContentType)
+		fmt.Fprintf(out, "Uploading file: %s\n", localPath)
+		fmt.Fprintf(out, "To: s3://%s/%s\n\n", bucket, key)
+
+		var skipped int
+		_, skipped, err = uo.skip.filterUnchanged(ctx, client, bucket, []localFile{{path: localPath, key: key, size: stat.Size()}})
+		if err == nil && skipped > 0 {
+			fmt.Fprintf(out, "Skipped: s3://%s/%s is unchanged\n", bucket, key)
+		} else if err == nil {
+			var etag string
+			if *multipart || stat.Size() > int64(*partSizeMB)*1024*1024 {
+				etag, err = uploadMultipart(ctx, client, localPath, bucket, key, int64(*partSizeMB)*1024*1024, uo)
+			} else {
+				etag, err = uploadSingleFile(ctx, client, localPath, bucket, key, uo)
+			}
+			if err == nil {
+				keys = []string{key}
+				bytes = stat.Size()
+				uploadManifest.add(key, bytes, etag)
+			}
+			transferLog.Transfer("upload", bucket, key, bytes, start, err)
 		}
 	}
 
+	// Whatever did upload is recorded, even when the run as a whole failed.
+	if merr := uploadManifest.write(); merr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", merr)
+	}
+
+	if opts.JSON {
+		result := report.New("upload", bucket, start, err)
+		result.Keys = keys
+		result.Bytes = bytes
+		report.Write(os.Stdout, result)
+	}
+
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "\nUpload cancelled.")
+		return 130
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "\n❌ Upload failed: %v\n", err)
+		printErrorTip(err, bucket)
 		return 1
 	}
 
 	elapsed := time.Since(start)
-	fmt.Printf("\n✓ Done! Uploaded in %s\n", formatDuration(elapsed))
+	fmt.Fprintf(out, "\n✓ Done! Uploaded in %s\n", ui.FormatDuration(elapsed))
+	return 0
+}
+
+// runDryRun prints what Run would upload, using the same file walk and key
+// mapping, after checking that the bucket is reachable.
+func runDryRun(ctx context.Context, client *s3.Client, opts config.Options, localPath string, stat os.FileInfo, bucket, keyPrefix string, multipart bool, partSize int64, filter *fileFilter, skip *skipOptions) int {
+	start := time.Now()
+	out := opts.Out()
+
+	found, err := s3ops.BucketExists(ctx, client, bucket)
+	if err == nil && !found {
+		err = fmt.Errorf("bucket %q does not exist", bucket)
+	}
+
+	var files []localFile
+	if err == nil {
+		if stat.IsDir() {
+			localPath = strings.TrimSuffix(localPath, string(os.PathSeparator))
+			files, err = collectFiles(localPath, keyPrefix+filepath.Base(localPath)+"/", filter)
+		} else {
+			files = []localFile{{path: localPath, key: keyPrefix + filepath.Base(localPath), size: stat.Size()}}
+		}
+	}
+	var skipped int
+	if err == nil {
+		files, skipped, err = skip.filterUnchanged(ctx, client, bucket, files)
+	}
+
+	var keys []string
+	var totalBytes int64
+	for _, f := range files {
+		keys = append(keys, f.key)
+		totalBytes += f.size
+	}
+
+	if opts.JSON {
+		result := report.New("upload", bucket, start, err)
+		result.Keys = keys
+		result.Bytes = totalBytes
+		report.Write(os.Stdout, result)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		printErrorTip(err, bucket)
+		return 1
+	}
+
+	fmt.Fprintln(out, "Dry run: nothing will be uploaded")
+	for _, f := range files {
+		line := fmt.Sprintf("  %10s  s3://%s/%s", ui.FormatSize(f.size), bucket, f.key)
+		if !stat.IsDir() && (multipart || f.size > partSize) {
+			size := effectivePartSize(f.size, partSize)
+			line += fmt.Sprintf("  (multipart, %d parts of %s)", (f.size+size-1)/size, ui.FormatSize(size))
+		}
+		fmt.Fprintln(out, line)
+	}
+	fmt.Fprintf(out, "\nWould upload %d files, %s\n", len(files), ui.FormatSize(totalBytes))
+	if skipped > 0 {
+		fmt.Fprintf(out, "Would skip %d unchanged files\n", skipped)
+	}
 	return 0
 }
 
-func uploadSingleFile(ctx context.Context, client *s3.Client, localPath, bucket, key string, meta map[string]string, guessContentType bool) error {
+func printErrorTip(err error, bucket string) {
+	switch s3ops.ClassifyError(err) {
+	case s3ops.KindAccessDenied:
+		fmt.Fprintln(os.Stderr, "Tip: 403/AccessDenied — credentials lack s3:PutObject on this bucket/prefix.")
+	case s3ops.KindNotFound:
+		fmt.Fprintf(os.Stderr, "Tip: bucket %q not found.\n", bucket)
+	case s3ops.KindWrongRegion:
+		fmt.Fprintln(os.Stderr, "Tip: bucket is in a different region. Try -region <region>.")
+	case s3ops.KindThrottled:
+		fmt.Fprintln(os.Stderr, "Tip: S3 is throttling requests — retry later.")
+	}
+}
+
+// uploadOptions carries the per-object settings shared by every upload path.
+type uploadOptions struct {
+	meta            map[string]string
+	contentTypeMode string
+	contentTypeMap  map[string]string
+	contentEncoding string
+	cacheControl    string
+	contentDisp     string
+	gzip            bool
+	forceGzip       bool
+	acl             types.ObjectCannedACL
+	checksumAlg     types.ChecksumAlgorithm
+	sse             *s3ops.SSECustomerKey
+	log             *metrics.Log
+	limiter         *rate.Limiter
+	continueOnError bool
+	retries         int
+	manifest        *manifest
+	filter          *fileFilter
+	skip            *skipOptions
+	out             io.Writer
+}
+
+func (uo uploadOptions) contentType(file *os.File, localPath string) string {
+	return detectContentType(file, localPath, uo.contentTypeMode, uo.contentTypeMap)
+}
+
+func uploadSingleFile(ctx context.Context, client *s3.Client, localPath, bucket, key string, uo uploadOptions) (string, error) {
 	file, err := os.Open(localPath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return "", fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
 	stat, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
+		return "", fmt.Errorf("failed to stat file: %w", err)
 	}
 
 	input := &s3.PutObjectInput{
 		Bucket:        aws.String(bucket),
 		Key:           aws.String(key),
-		Body:          file,
+		Body:          ratelimit.NewReader(ctx, file, uo.limiter),
 		ContentLength: aws.Int64(stat.Size()),
 	}
 
-	if guessContentType {
-		contentType := guessContentTypeFromExt(localPath)
-		if contentType != "" {
-			input.ContentType = aws.String(contentType)
+	if contentType := uo.contentType(file, localPath); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	if uo.contentEncoding != "" {
+		input.ContentEncoding = aws.String(uo.contentEncoding)
+	}
+	if uo.cacheControl != "" {
+		input.CacheControl = aws.String(uo.cacheControl)
+	}
+	if uo.contentDisp != "" {
+		input.ContentDisposition = aws.String(uo.contentDisp)
+	}
+
+	if uo.shouldGzip(localPath) {
+		data, err := gzipFile(file)
+		if err != nil {
+			return "", err
 		}
+		input.Body = ratelimit.NewReader(ctx, bytes.NewReader(data), uo.limiter)
+		input.ContentLength = aws.Int64(int64(len(data)))
+		input.ContentEncoding = aws.String("gzip")
 	}
 
-	if len(meta) > 0 {
-		input.Metadata = meta
+	if len(uo.meta) > 0 {
+		input.Metadata = uo.meta
 	}
 
-	_, err = client.PutObject(ctx, input)
+	if uo.acl != "" {
+		input.ACL = uo.acl
+	}
+
+	if uo.checksumAlg != "" {
+		input.ChecksumAlgorithm = uo.checksumAlg
+	}
+
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = uo.sse.Fields()
+
+	resp, err := client.PutObject(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to upload: %w", err)
+		return "", fmt.Errorf("failed to upload: %w", err)
+	}
+	etag := aws.ToString(resp.ETag)
+
+	if uo.checksumAlg != "" {
+		expected := pickChecksum(uo.checksumAlg, resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumSHA1, resp.ChecksumSHA256)
+		return etag, verifyChecksum(ctx, client, bucket, key, uo.checksumAlg, expected, uo.sse)
 	}
 
-	return nil
+	return etag, nil
 }
 
-func uploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket, key string, partSize int64, meta map[string]string) error {
+const (
+	maxParts    = 10000
+	minPartSize = 5 * 1024 * 1024
+)
+
+// effectivePartSize raises partSize to S3's 5 MB minimum and, when the file
+// would need more than 10,000 parts, to the smallest whole number of MB that
+// fits. The last part is exempt from the minimum, so a single short part is
+// fine.
+func effectivePartSize(totalSize, partSize int64) int64 {
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+	if (totalSize+partSize-1)/partSize > maxParts {
+		const mb = 1024 * 1024
+		partSize = (totalSize + maxParts - 1) / maxParts
+		partSize = (partSize + mb - 1) / mb * mb
+	}
+	return partSize
+}
+
+func uploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket, key string, partSize int64, uo uploadOptions) (string, error) {
 	file, err := os.Open(localPath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return "", fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
 	stat, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
+		return "", fmt.Errorf("failed to stat file: %w", err)
 	}
 
 	totalSize := stat.Size()
@@ -191,240 +555,273 @@ func uploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket,
 	if partSizeBytes <= 0 {
 		partSizeBytes = 10 * 1024 * 1024
 	}
+	if effective := effectivePartSize(totalSize, partSizeBytes); effective != partSizeBytes {
+		fmt.Fprintf(os.Stderr, "Warning: part size increased from %s to %s (S3 allows at most %d parts of at least %s)\n",
+			ui.FormatSize(partSizeBytes), ui.FormatSize(effective), maxParts, ui.FormatSize(minPartSize))
+		partSizeBytes = effective
+	}
 
-	createResp, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+	createInput := &s3.CreateMultipartUploadInput{
 		Bucket:   aws.String(bucket),
 		Key:      aws.String(key),
-		Metadata: meta,
-	})
+		Metadata: uo.meta,
+	}
+	if contentType := uo.contentType(file, localPath); contentType != "" {
+		createInput.ContentType = aws.String(contentType)
+	}
+	if uo.contentEncoding != "" {
+		createInput.ContentEncoding = aws.String(uo.contentEncoding)
+	}
+	if uo.cacheControl != "" {
+		createInput.CacheControl = aws.String(uo.cacheControl)
+	}
+	if uo.contentDisp != "" {
+		createInput.ContentDisposition = aws.String(uo.contentDisp)
+	}
+	if uo.acl != "" {
+		createInput.ACL = uo.acl
+	}
+	if uo.checksumAlg != "" {
+		createInput.ChecksumAlgorithm = uo.checksumAlg
+	}
+	createInput.SSECustomerAlgorithm, createInput.SSECustomerKey, createInput.SSECustomerKeyMD5 = uo.sse.Fields()
+
+	consumed := &countingReader{r: file}
+	plain := !ui.IsTerminal(uo.out)
+	var plog ui.ProgressLog
+	var body io.Reader = consumed
+	if uo.shouldGzip(localPath) {
+		gz := gzipStream(consumed)
+		defer gz.Close()
+		body = gz
+		createInput.ContentEncoding = aws.String("gzip")
+		fmt.Fprintf(uo.out, "Multipart upload: gzip-compressed, %d MB parts\n", partSizeBytes/(1024*1024))
+	} else {
+		fmt.Fprintf(uo.out, "Multipart upload: %d parts of %s\n", (totalSize+partSizeBytes-1)/partSizeBytes, ui.FormatSize(partSizeBytes))
+	}
+
+	createResp, err := client.CreateMultipartUpload(ctx, createInput)
 	if err != nil {
-		return fmt.Errorf("failed to start multipart upload: %w", err)
+		return "", fmt.Errorf("failed to start multipart upload: %w", err)
 	}
 
 	uploadID := createResp.UploadId
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := s3ops.AbortMultipartUpload(ctx, client, bucket, key, uploadID); err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: %v (upload ID %s)\n", err, aws.ToString(uploadID))
+			return
+		}
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "\nInterrupted: multipart upload aborted, no parts left on S3")
+		}
+	}()
 
 	var completedParts []types.CompletedPart
+	var uploaded int64
 	partNumber := 1
-	offset := int64(0)
-
-	fmt.Printf("Multipart upload: %d parts\n", (totalSize+partSizeBytes-1)/partSizeBytes)
+	buf := make([]byte, partSizeBytes)
 
-	for offset < totalSize {
-		remaining := totalSize - offset
-		chunkSize := partSizeBytes
-		if remaining < chunkSize {
-			chunkSize = remaining
+	for {
+		n, err := io.ReadFull(body, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return "", fmt.Errorf("failed to read part %d: %w", partNumber, err)
 		}
-
-		buf := make([]byte, chunkSize)
-		_, err := file.ReadAt(buf, offset)
-		if err != nil && err != io.EOF {
-			client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(bucket),
-				Key:      aws.String(key),
-				UploadId: uploadID,
-			})
-			return fmt.Errorf("failed to read at offset %d: %w", offset, err)
+		if n == 0 {
+			break
 		}
 
-		uploadResp, err := client.UploadPart(ctx, &s3.UploadPartInput{
+		partInput := &s3.UploadPartInput{
 			Bucket:     aws.String(bucket),
 			Key:        aws.String(key),
 			UploadId:   uploadID,
 			PartNumber: aws.Int32(int32(partNumber)),
-			Body:       strings.NewReader(string(buf)),
-		})
+			Body:       ratelimit.NewReader(ctx, bytes.NewReader(buf[:n]), uo.limiter),
+		}
+		if uo.checksumAlg != "" {
+			setPartChecksum(partInput, uo.checksumAlg, buf[:n])
+		}
+		partInput.SSECustomerAlgorithm, partInput.SSECustomerKey, partInput.SSECustomerKeyMD5 = uo.sse.Fields()
+
+		uploadResp, err := client.UploadPart(ctx, partInput)
 		if err != nil {
-			client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(bucket),
-				Key:      aws.String(key),
-				UploadId: uploadID,
-			})
-			return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+			return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
 		}
 
-		completedParts = append(completedParts, types.CompletedPart{
-			ETag:       uploadResp.ETag,
-			PartNumber: aws.Int32(int32(partNumber)),
-		})
+		completedParts = append(completedParts, completedPart(uploadResp, partNumber))
+		uploaded += int64(n)
 
-		offset += chunkSize
 		partNumber++
 
-		pct := float64(offset) / float64(totalSize) * 100
-		fmt.Printf("\rProgress: %.1f%%", pct)
+		pct := float64(consumed.n.Load()) / float64(totalSize) * 100
+		if !plain {
+			fmt.Fprintf(uo.out, "\rProgress: %.1f%%", pct)
+		} else if plog.Due(pct) {
+			fmt.Fprintf(uo.out, "Progress: %.1f%%\n", pct)
+		}
+	}
+	if !plain {
+		fmt.Fprintln(uo.out)
 	}
-	fmt.Println()
 
-	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+	completeInput := &s3.CompleteMultipartUploadInput{
 		Bucket:          aws.String(bucket),
 		Key:             aws.String(key),
 		UploadId:        uploadID,
 		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to complete multipart upload: %w", err)
 	}
+	completeInput.SSECustomerAlgorithm, completeInput.SSECustomerKey, completeInput.SSECustomerKeyMD5 = uo.sse.Fields()
 
-	return nil
-}
-
-func uploadDirectory(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, meta map[string]string, guessContentType bool) error {
-	entries, err := os.ReadDir(localDir)
+	completeResp, err := s3ops.CompleteMultipartUpload(ctx, client, completeInput, uploaded)
 	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
+		return "", fmt.Errorf("failed to complete multipart upload: %w", err)
 	}
+	completed = true
+	etag := aws.ToString(completeResp.ETag)
 
-	var totalFiles int
-	var totalBytes int64
-	for _, e := range entries {
-		if !e.IsDir() {
-			info, _ := e.Info()
-			totalFiles++
-			totalBytes += info.Size()
-		}
+	if uo.checksumAlg != "" {
+		expected := pickChecksum(uo.checksumAlg, completeResp.ChecksumCRC32, completeResp.ChecksumCRC32C, completeResp.ChecksumSHA1, completeResp.ChecksumSHA256)
+		return etag, verifyChecksum(ctx, client, bucket, key, uo.checksumAlg, expected, uo.sse)
 	}
 
-	fmt.Printf("Total files: %d, Total size: %s\n\n", totalFiles, formatSize(totalBytes))
-
-	uploaded := 0
-	var uploadedBytes int64
+	return etag, nil
+}
 
-	for _, e := range entries {
-		path := filepath.Join(localDir, e.Name())
-		key := prefix + e.Name()
+type localFile struct {
+	path string
+	key  string
+	size int64
+}
 
+// collectFiles walks localDir and maps every regular file the filter allows
+// to a key below prefix, so totals are known before the first upload starts.
+func collectFiles(localDir, prefix string, filter *fileFilter) ([]localFile, error) {
+	var files []localFile
+	err := filepath.WalkDir(localDir, func(path string, e os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if e.IsDir() {
-			err := uploadDirectoryRecursive(ctx, client, path, bucket, key+"/", meta, guessContentType, &uploaded, &uploadedBytes, totalBytes)
-			if err != nil {
-				return err
-			}
-		} else {
-			err := uploadSingleFile(ctx, client, path, bucket, key, meta, guessContentType)
-			if err != nil {
-				return fmt.Errorf("failed to upload %s: %w", e.Name(), err)
-			}
-			info, _ := e.Info()
-			uploadedBytes += info.Size()
-			uploaded++
-			pct := float64(uploadedBytes) / float64(totalBytes) * 100
-			fmt.Printf("\rUploaded %d/%d files (%.1f%%)", uploaded, totalFiles, pct)
+			return nil
 		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !filter.allows(rel) {
+			return nil
+		}
+		files = append(files, localFile{
+			path: path,
+			key:  prefix + rel,
+			size: info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
-	fmt.Println()
-
-	return nil
+	return files, nil
 }
 
-func uploadDirectoryRecursive(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, meta map[string]string, guessContentType bool, uploaded *int, uploadedBytes *int64, totalBytes int64) error {
-	entries, err := os.ReadDir(localDir)
+func uploadDirectory(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, uo uploadOptions) ([]string, int64, error) {
+	files, err := collectFiles(localDir, prefix, uo.filter)
 	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
+		return nil, 0, err
+	}
+	files, skipped, err := uo.skip.filterUnchanged(ctx, client, bucket, files)
+	if err != nil {
+		return nil, 0, err
+	}
+	if skipped > 0 {
+		fmt.Fprintf(uo.out, "Skipping %d unchanged files already in S3\n", skipped)
 	}
 
-	for _, e := range entries {
-		path := filepath.Join(localDir, e.Name())
-		key := prefix + e.Name()
+	var totalBytes int64
+	for _, f := range files {
+		totalBytes += f.size
+	}
 
-		if e.IsDir() {
-			err := uploadDirectoryRecursive(ctx, client, path, bucket, key+"/", meta, guessContentType, uploaded, uploadedBytes, totalBytes)
-			if err != nil {
-				return err
-			}
-		} else {
-			err := uploadSingleFile(ctx, client, path, bucket, key, meta, guessContentType)
-			if err != nil {
-				return fmt.Errorf("failed to upload %s: %w", e.Name(), err)
+	fmt.Fprintf(uo.out, "Total files: %d, Total size: %s\n\n", len(files), ui.FormatSize(totalBytes))
+
+	var keys []string
+	var failed []string
+	var uploadedBytes int64
+	plain := !ui.IsTerminal(uo.out)
+	var plog ui.ProgressLog
+
+	for _, f := range files {
+		start := time.Now()
+		var etag string
+		err := withRetry(ctx, uo.retries, func() error {
+			var err error
+			etag, err = uploadSingleFile(ctx, client, f.path, bucket, f.key, uo)
+			return err
+		})
+		if err != nil {
+			uo.log.Transfer("upload", bucket, f.key, 0, start, err)
+			if !uo.continueOnError || ctx.Err() != nil {
+				return keys, uploadedBytes, fmt.Errorf("failed to upload %s: %w", f.path, err)
 			}
-			info, _ := e.Info()
-			*uploadedBytes += info.Size()
-			*uploaded++
-			pct := float64(*uploadedBytes) / float64(totalBytes) * 100
-			fmt.Printf("\rUploaded %d files (%.1f%%)", *uploaded, pct)
+			fmt.Fprintf(os.Stderr, "\nFailed: %s: %v\n", f.path, err)
+			failed = append(failed, f.key)
+			continue
+		}
+		uo.log.Transfer("upload", bucket, f.key, f.size, start, nil)
+		uo.manifest.add(f.key, f.size, etag)
+		keys = append(keys, f.key)
+		uploadedBytes += f.size
+		pct := 100.0
+		if totalBytes > 0 {
+			pct = float64(uploadedBytes) / float64(totalBytes) * 100
+		}
+		if !plain {
+			fmt.Fprintf(uo.out, "\rUploaded %d/%d files (%.1f%%)", len(keys), len(files), pct)
+		} else if plog.Due(pct) {
+			fmt.Fprintf(uo.out, "Uploaded %d/%d files (%.1f%%)\n", len(keys), len(files), pct)
 		}
 	}
-
-	return nil
-}
-
-func parseMetadata(s string) map[string]string {
-	meta := make(map[string]string)
-	if s == "" {
-		return meta
+	if !plain {
+		fmt.Fprintln(uo.out)
 	}
-	pairs := strings.Split(s, ",")
-	for _, pair := range pairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) == 2 {
-			meta[parts[0]] = parts[1]
+
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d of %d files failed:\n", len(failed), len(files))
+		for _, key := range failed {
+			fmt.Fprintf(os.Stderr, "  s3://%s/%s\n", bucket, key)
 		}
+		return keys, uploadedBytes, fmt.Errorf("%d of %d files failed to upload", len(failed), len(files))
 	}
-	return meta
-}
 
-func guessContentTypeFromExt(path string) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".html", ".htm":
-		return "text/html"
-	case ".css":
-		return "text/css"
-	case ".js":
-		return "application/javascript"
-	case ".json":
-		return "application/json"
-	case ".xml":
-		return "application/xml"
-	case ".txt":
-		return "text/plain"
-	case ".jpg", ".jpeg":
-		return "image/jpeg"
-	case ".png":
-		return "image/png"
-	case ".gif":
-		return "image/gif"
-	case ".pdf":
-		return "application/pdf"
-	case ".zip":
-		return "application/zip"
-	case ".tar":
-		return "application/x-tar"
-	case ".gz", ".tgz":
-		return "application/gzip"
-	case ".svg":
-		return "image/svg+xml"
-	case ".ico":
-		return "image/x-icon"
-	default:
-		return "application/octet-stream"
-	}
+	return keys, uploadedBytes, nil
 }
 
-func formatDuration(d time.Duration) string {
-	d = d.Round(time.Second)
-	h := d / time.Hour
-	d -= h * time.Hour
-	m := d / time.Minute
-	d -= m * time.Minute
-	s := d / time.Second
-	if h > 0 {
-		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
-	}
-	if m > 0 {
-		return fmt.Sprintf("%dm%02ds", m, s)
-	}
-	return fmt.Sprintf("%ds", s)
-}
+// withRetry calls fn up to retries+1 times, backing off 1s, 2s, 4s, ...
+// between attempts. Errors that another attempt cannot fix (permissions,
+// missing bucket, wrong region) and cancellation end it early.
+func withRetry(ctx context.Context, retries int, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || ctx.Err() != nil {
+			return err
+		}
+		switch s3ops.ClassifyError(err) {
+		case s3ops.KindAccessDenied, s3ops.KindNotFound, s3ops.KindWrongRegion:
+			return err
+		}
 
-func formatSize(size int64) string {
-	if size < 1024 {
-		return fmt.Sprintf("%d B", size)
-	}
-	if size < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(size)/1024)
-	}
-	if size < 1024*1024*1024 {
-		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Second << attempt):
+		}
 	}
-	return fmt.Sprintf("%.1f GB", float64(size)/(1024*1024*1024))
 }
@@ -0,0 +1,33 @@
+package upload
+
+import "testing"
+
+func TestEffectivePartSize(t *testing.T) {
+	const mb = 1024 * 1024
+	const gb = 1024 * mb
+	tests := []struct {
+		name      string
+		totalSize int64
+		partSize  int64
+		want      int64
+	}{
+		{"within limits", 100 * mb, 10 * mb, 10 * mb},
+		{"below minimum", 100 * mb, 1 * mb, minPartSize},
+		{"small file below minimum", 1024, 1, minPartSize},
+		{"exactly 10,000 parts", 10000 * 10 * mb, 10 * mb, 10 * mb},
+		{"one byte over 10,000 parts", 10000*10*mb + 1, 10 * mb, 11 * mb},
+		{"too many minimum parts", 100 * gb, 1 * mb, 11 * mb},
+		{"5 TB object", 5 * 1024 * gb, 10 * mb, 525 * mb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := effectivePartSize(tt.totalSize, tt.partSize)
+			if got != tt.want {
+				t.Fatalf("effectivePartSize(%d, %d) = %d, want %d", tt.totalSize, tt.partSize, got, tt.want)
+			}
+			if parts := (tt.totalSize + got - 1) / got; parts > maxParts {
+				t.Errorf("%d parts of %d bytes, more than %d", parts, got, maxParts)
+			}
+		})
+	}
+}
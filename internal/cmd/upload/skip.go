@@ -0,0 +1,70 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// skipOptions configures -skip-existing. A nil *skipOptions uploads
+// everything.
+type skipOptions struct {
+	// checksum also requires the object's ETag to match the local MD5
+	// instead of trusting an equal size.
+	checksum bool
+	sse      *s3ops.SSECustomerKey
+}
+
+// filterUnchanged heads each file's key and drops the files already in S3:
+// those whose object has the same size and, with checksum, the same
+// content according to its ETag. It returns the files still to upload and
+// how many were skipped.
+func (so *skipOptions) filterUnchanged(ctx context.Context, client *s3.Client, bucket string, files []localFile) ([]localFile, int, error) {
+	if so == nil {
+		return files, 0, nil
+	}
+
+	skip := make([]bool, len(files))
+	var pairs []s3ops.ChecksumPair
+	var candidates []int
+	for i, f := range files {
+		meta, err := s3ops.HeadObject(ctx, client, bucket, f.key, s3ops.WithSSECustomerKey(so.sse))
+		if s3ops.ClassifyError(err) == s3ops.KindNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to check s3://%s/%s: %w", bucket, f.key, err)
+		}
+		if meta.Size != f.size {
+			continue
+		}
+		if !so.checksum {
+			skip[i] = true
+			continue
+		}
+		pairs = append(pairs, s3ops.ChecksumPair{
+			LocalPath: f.path,
+			Remote:    s3ops.ObjectInfo{Key: f.key, Size: meta.Size, ETag: meta.ETag},
+		})
+		candidates = append(candidates, i)
+	}
+
+	for i, r := range s3ops.CompareChecksums(ctx, client, bucket, pairs, s3ops.DefaultHashWorkers) {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not compare %s, uploading it: %v\n", r.LocalPath, r.Err)
+		}
+		skip[candidates[i]] = r.Match
+	}
+
+	var upload []localFile
+	for i, f := range files {
+		if !skip[i] {
+			upload = append(upload, f)
+		}
+	}
+	return upload, len(files) - len(upload), nil
+}
@@ -0,0 +1,211 @@
+package versions
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+	"s3-client/internal/shared/ui"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("versions", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client versions [flags] s3://bucket/key")
+	fmt.Fprintln(os.Stderr, "       s3-client versions [flags] s3://bucket/prefix/")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "List every version and delete marker of a key, or of all keys under a prefix")
+	fmt.Fprintln(os.Stderr, "(a trailing / or a bare bucket). With -keep N, permanently delete all but the")
+	fmt.Fprintln(os.Stderr, "N most recent non-current versions of each key; the current version is never")
+	fmt.Fprintln(os.Stderr, "touched. Without -force, -keep only shows what would be deleted.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client versions s3://my-bucket/config.json")
+	fmt.Fprintln(os.Stderr, "  s3-client versions -keep 3 s3://my-bucket/reports/")
+	fmt.Fprintln(os.Stderr, "  s3-client versions -keep 0 -force s3://my-bucket/reports/")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	keep := fs.Int("keep", -1, "Prune: keep this many non-current versions per key and delete the rest")
+	force := fs.Bool("force", false, "With -keep, actually delete the pruned versions")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	prune := flagSet(fs, "keep")
+	if prune && *keep < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -keep must not be negative")
+		return 1
+	}
+	if *force && !prune {
+		fmt.Fprintln(os.Stderr, "Error: -force only applies with -keep")
+		return 1
+	}
+
+	bucket, prefix, err := s3uri.ParsePrefix(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	// Without a trailing slash the argument names one key, and versions of
+	// longer keys sharing it as a prefix are dropped.
+	exactKey := prefix != "" && !strings.HasSuffix(prefix, "/")
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	versions, err := s3ops.ListObjectVersions(ctx, client, bucket, prefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if exactKey {
+		matched := versions[:0]
+		for _, v := range versions {
+			if v.Key == prefix {
+				matched = append(matched, v)
+			}
+		}
+		versions = matched
+	}
+
+	out := opts.Out()
+	if len(versions) == 0 {
+		fmt.Fprintf(out, "No versions found under s3://%s/%s\n", bucket, prefix)
+		return 0
+	}
+
+	if !prune {
+		for _, v := range versions {
+			printVersion(out, v)
+		}
+		return 0
+	}
+
+	doomed := pruneCandidates(versions, *keep)
+	if len(doomed) == 0 {
+		fmt.Fprintf(out, "Nothing to prune: no key has more than %d non-current versions\n", *keep)
+		return 0
+	}
+
+	var size int64
+	for _, v := range doomed {
+		printVersion(out, v)
+		size += v.Size
+	}
+	fmt.Fprintf(out, "\n%d versions (%s) to delete\n", len(doomed), ui.FormatSize(size))
+
+	if !*force {
+		fmt.Fprintln(os.Stderr, "Nothing deleted; re-run with -force to delete these versions permanently.")
+		return 0
+	}
+
+	refs := make([]s3ops.ObjectVersionRef, len(doomed))
+	for i, v := range doomed {
+		refs[i] = s3ops.ObjectVersionRef{Key: v.Key, VersionID: v.VersionID}
+	}
+	results, err := s3ops.DeleteObjectVersions(ctx, client, bucket, refs)
+	deleted, failed := 0, 0
+	for _, r := range results {
+		if r.Deleted {
+			deleted++
+			continue
+		}
+		failed++
+		fmt.Fprintf(os.Stderr, "Failed to delete %s (version %s): %v\n", r.Key, r.VersionID, r.Error)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Deleted %d versions\n", deleted)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d versions could not be deleted\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// pruneCandidates returns, for each key, the non-current versions and
+// delete markers beyond the keep most recent. versions must be grouped by
+// key, newest first, as ListObjectVersions returns them.
+func pruneCandidates(versions []s3ops.ObjectVersion, keep int) []s3ops.ObjectVersion {
+	var doomed []s3ops.ObjectVersion
+	key, seen := "", 0
+	for _, v := range versions {
+		if v.Key != key {
+			key, seen = v.Key, 0
+		}
+		if v.IsLatest {
+			continue
+		}
+		seen++
+		if seen > keep {
+			doomed = append(doomed, v)
+		}
+	}
+	return doomed
+}
+
+func printVersion(w io.Writer, v s3ops.ObjectVersion) {
+	status := ""
+	switch {
+	case v.IsDeleteMarker && v.IsLatest:
+		status = "delete marker (latest)"
+	case v.IsDeleteMarker:
+		status = "delete marker"
+	case v.IsLatest:
+		status = "latest"
+	}
+	size := ui.FormatSize(v.Size)
+	if v.IsDeleteMarker {
+		size = "-"
+	}
+	fmt.Fprintf(w, "%s  %10s  %-32s  %-22s  %s\n",
+		v.LastModified.Local().Format("2006-01-02 15:04:05"), size, v.VersionID, status, v.Key)
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
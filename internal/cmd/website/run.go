@@ -0,0 +1,152 @@
+package website
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/s3uri"
+	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("website", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client website [flags] s3://bucket")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show, set, or delete the static website configuration of an S3 bucket.")
+	fmt.Fprintln(os.Stderr, "-index and -error override the matching fields of -file.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client website -show s3://my-site")
+	fmt.Fprintln(os.Stderr, "  s3-client website -index index.html -error 404.html s3://my-site")
+	fmt.Fprintln(os.Stderr, "  s3-client website -file website.json s3://my-site")
+	fmt.Fprintln(os.Stderr, "  s3-client website -delete s3://my-site")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Config file format (JSON):")
+	fmt.Fprintln(os.Stderr, `  {"IndexDocument":"index.html","ErrorDocument":"404.html",`)
+	fmt.Fprintln(os.Stderr, `   "RoutingRules":[{"KeyPrefixEquals":"docs/","ReplaceKeyPrefixWith":"documents/"}]}`)
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	file := fs.String("file", "", "Path to website configuration file (JSON)")
+	index := fs.String("index", "", "Index document suffix, e.g. index.html")
+	errorDoc := fs.String("error", "", "Error document key, e.g. 404.html")
+	delete := fs.Bool("delete", false, "Delete the website configuration")
+	show := fs.Bool("show", false, "Show the current website configuration")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	bucket, err := s3uri.ParseBucket(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	setting := *file != "" || *index != "" || *errorDoc != ""
+	if !*show && !*delete && !setting {
+		fmt.Fprintln(os.Stderr, "Error: Must specify one of -show, -file/-index/-error, or -delete")
+		fs.Usage()
+		return 1
+	}
+
+	website := &s3ops.WebsiteConfiguration{}
+	if setting {
+		if *file != "" {
+			data, err := os.ReadFile(*file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading website file: %v\n", err)
+				return 1
+			}
+			website, err = s3ops.ParseWebsiteConfig(data)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing website file: %v\n", err)
+				return 1
+			}
+		}
+		if *index != "" {
+			website.IndexDocument = *index
+		}
+		if *errorDoc != "" {
+			website.ErrorDocument = *errorDoc
+		}
+		if err := s3ops.ValidateWebsiteConfig(website); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	if *show {
+		current, err := s3ops.GetBucketWebsite(ctx, client, bucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if current == nil {
+			fmt.Println("No website configuration set.")
+			return 0
+		}
+		data, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+	}
+
+	if *delete {
+		if err := s3ops.DeleteBucketWebsite(ctx, client, bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Website configuration deleted for bucket %s\n", bucket)
+		return 0
+	}
+
+	if err := s3ops.PutBucketWebsite(ctx, client, bucket, website); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Website configuration set for bucket %s", bucket)
+	if len(website.RoutingRules) > 0 {
+		fmt.Printf(" (%d routing rules)", len(website.RoutingRules))
+	}
+	fmt.Println()
+	return 0
+}
@@ -0,0 +1,116 @@
+package whoami
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"s3-client/internal/shared/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("whoami", flag.ContinueOnError)
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: s3-client whoami [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Show the AWS account and identity the resolved credentials belong to, using")
+	fmt.Fprintln(os.Stderr, "STS GetCallerIdentity. With -list-profiles, list the profiles defined in the")
+	fmt.Fprintln(os.Stderr, "shared config and credentials files instead; the one in use is marked with *.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  s3-client whoami")
+	fmt.Fprintln(os.Stderr, "  s3-client whoami -profile prod")
+	fmt.Fprintln(os.Stderr, "  s3-client whoami -list-profiles")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	fs.PrintDefaults()
+}
+
+func Run(args []string) int {
+	fs := newFlagSet()
+	listProfiles := fs.Bool("list-profiles", false, "List profiles from the shared config files")
+
+	opts := &config.Options{}
+	config.AddFlags(fs, opts)
+
+	fs.Usage = func() {
+		printUsage(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *listProfiles {
+		return printProfiles(activeProfile(opts.Profile))
+	}
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx, *opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load AWS config: %v\n", err)
+		return 1
+	}
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no usable credentials: %v\n", err)
+		return 1
+	}
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if opts.Endpoint != "" {
+			fmt.Fprintln(os.Stderr, "Tip: with -endpoint the STS call goes to that endpoint too; S3-compatible services often don't implement it.")
+		}
+		return 1
+	}
+
+	out := opts.Out()
+	fmt.Fprintf(out, "Account:     %s\n", aws.ToString(identity.Account))
+	fmt.Fprintf(out, "ARN:         %s\n", aws.ToString(identity.Arn))
+	fmt.Fprintf(out, "User ID:     %s\n", aws.ToString(identity.UserId))
+	fmt.Fprintf(out, "Profile:     %s\n", activeProfile(opts.Profile))
+	fmt.Fprintf(out, "Region:      %s\n", cfg.Region)
+	fmt.Fprintf(out, "Credentials: %s\n", creds.Source)
+	return 0
+}
+
+// activeProfile mirrors the SDK's choice: -profile, then AWS_PROFILE, then
+// "default".
+func activeProfile(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if p := os.Getenv("AWS_PROFILE"); p != "" {
+		return p
+	}
+	return "default"
+}
+
+func printProfiles(active string) int {
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No profiles found.")
+		return 0
+	}
+	for _, p := range profiles {
+		marker := " "
+		if p == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, p)
+	}
+	return 0
+}
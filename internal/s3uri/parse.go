@@ -2,6 +2,7 @@ package s3uri
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
@@ -25,3 +26,89 @@ func Parse(uri string) (bucket, key string, err error) {
 	}
 	return bucket, key, nil
 }
+
+// ParseBucket extracts the bucket from an S3 URI (s3://bucket or s3://bucket/key),
+// ignoring any key portion.
+func ParseBucket(uri string) (string, error) {
+	if !strings.HasPrefix(uri, "s3://") {
+		return "", fmt.Errorf("invalid S3 URI %q: must start with s3://", uri)
+	}
+	rest := strings.TrimPrefix(uri, "s3://")
+	if idx := strings.IndexByte(rest, '/'); idx != -1 {
+		rest = rest[:idx]
+	}
+	if rest == "" {
+		return "", fmt.Errorf("invalid S3 URI %q: bucket name is empty", uri)
+	}
+	return rest, nil
+}
+
+// ParsePrefix extracts bucket and an optional key prefix from an S3 URI
+// (s3://bucket, s3://bucket/ or s3://bucket/some/prefix).
+func ParsePrefix(uri string) (bucket, prefix string, err error) {
+	bucket, err = ParseBucket(uri)
+	if err != nil {
+		return "", "", err
+	}
+	rest := strings.TrimPrefix(uri, "s3://"+bucket)
+	return bucket, strings.TrimPrefix(rest, "/"), nil
+}
+
+// HasWildcard reports whether key contains glob metacharacters understood by
+// path.Match (*, ? or [).
+func HasWildcard(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// LiteralPrefix returns the part of a glob key before its first wildcard,
+// suitable as a ListObjectsV2 prefix.
+func LiteralPrefix(key string) string {
+	if idx := strings.IndexAny(key, "*?["); idx != -1 {
+		return key[:idx]
+	}
+	return key
+}
+
+// NormalizeKey cleans a key built from local paths: the OS path separator
+// becomes "/", a Windows drive letter is dropped, runs of "/" collapse to
+// one, "." segments are removed and ".." removes the segment before it
+// (never climbing above the start of the key). Leading "/" are removed; a
+// trailing "/" is kept so prefixes stay prefixes.
+func NormalizeKey(key string) string {
+	return normalizeKey(key, filepath.Separator)
+}
+
+// normalizeKey is NormalizeKey for a given path separator, so Windows paths
+// can be tested on any OS.
+func normalizeKey(key string, sep rune) string {
+	if sep != '/' {
+		key = strings.ReplaceAll(key, string(sep), "/")
+	}
+	if sep == '\\' && len(key) >= 2 && key[1] == ':' && isLetter(key[0]) {
+		key = key[2:]
+	}
+
+	trailing := strings.HasSuffix(key, "/")
+	var segments []string
+	for _, seg := range strings.Split(key, "/") {
+		switch seg {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, seg)
+		}
+	}
+
+	key = strings.Join(segments, "/")
+	if trailing && key != "" {
+		key += "/"
+	}
+	return key
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,78 @@
+package s3uri
+
+import "testing"
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"data/file.txt", "data/file.txt"},
+		{"data//file.txt", "data/file.txt"},
+		{"/data///sub//", "data/sub/"},
+		{"dir/", "dir/"},
+		{"./dir/./file", "dir/file"},
+		{"a/b/../c", "a/c"},
+		{"../../etc/passwd", "etc/passwd"},
+		{"", ""},
+		{"/", ""},
+		{`back\slash`, `back\slash`}, // a legal key character on Unix
+	}
+	for _, tt := range tests {
+		if got := normalizeKey(tt.key, '/'); got != tt.want {
+			t.Errorf("normalizeKey(%q, '/') = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeKeyWindows(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{`data\file.txt`, "data/file.txt"},
+		{`data\\sub\file.txt`, "data/sub/file.txt"},
+		{`\data\sub\`, "data/sub/"},
+		{`data/mixed\sep`, "data/mixed/sep"},
+		{`C:\Users\me\file.txt`, "Users/me/file.txt"},
+		{`d:relative\file`, "relative/file"},
+		{`..\file.txt`, "file.txt"},
+		{`a\b\..\..\..\c`, "c"},
+		{`photos\.\2024\..\2025\`, "photos/2025/"},
+		{`C:`, ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeKey(tt.key, '\\'); got != tt.want {
+			t.Errorf("normalizeKey(%q, '\\\\') = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	bucket, key, err := Parse("s3://bucket/dir/file.txt")
+	if err != nil || bucket != "bucket" || key != "dir/file.txt" {
+		t.Errorf("Parse() = %q, %q, %v; want bucket, dir/file.txt, nil", bucket, key, err)
+	}
+	for _, uri := range []string{"bucket/key", "s3://bucket", "s3://bucket/", "s3:///key"} {
+		if _, _, err := Parse(uri); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", uri)
+		}
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		uri            string
+		bucket, prefix string
+	}{
+		{"s3://bucket", "bucket", ""},
+		{"s3://bucket/", "bucket", ""},
+		{"s3://bucket/logs/2024/", "bucket", "logs/2024/"},
+	}
+	for _, tt := range tests {
+		bucket, prefix, err := ParsePrefix(tt.uri)
+		if err != nil || bucket != tt.bucket || prefix != tt.prefix {
+			t.Errorf("ParsePrefix(%q) = %q, %q, %v; want %q, %q", tt.uri, bucket, prefix, err, tt.bucket, tt.prefix)
+		}
+	}
+}
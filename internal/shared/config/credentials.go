@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Credentials builds the credentials provider for LoadWithOptions. base is
+// the config resolved from Options; its own Credentials are the SDK default
+// chain (environment, shared files, SSO, instance roles), which providers
+// such as AssumeRoleCredentials use as their source identity.
+type Credentials func(ctx context.Context, base aws.Config) (aws.CredentialsProvider, error)
+
+// StaticCredentials uses fixed keys; sessionToken may be empty.
+func StaticCredentials(accessKey, secretKey, sessionToken string) Credentials {
+	return func(ctx context.Context, base aws.Config) (aws.CredentialsProvider, error) {
+		return credentials.NewStaticCredentialsProvider(accessKey, secretKey, sessionToken), nil
+	}
+}
+
+// ProviderCredentials uses a provider the caller built itself.
+func ProviderCredentials(p aws.CredentialsProvider) Credentials {
+	return func(ctx context.Context, base aws.Config) (aws.CredentialsProvider, error) {
+		return p, nil
+	}
+}
+
+// AssumeRoleCredentials assumes roleARN with the base credentials.
+// externalID may be empty.
+func AssumeRoleCredentials(roleARN, sessionName, externalID string) Credentials {
+	return func(ctx context.Context, base aws.Config) (aws.CredentialsProvider, error) {
+		return stscreds.NewAssumeRoleProvider(sts.NewFromConfig(base), roleARN, func(o *stscreds.AssumeRoleOptions) {
+			if sessionName != "" {
+				o.RoleSessionName = sessionName
+			}
+			if externalID != "" {
+				o.ExternalID = aws.String(externalID)
+			}
+		}), nil
+	}
+}
+
+// SSOCredentials reads an IAM Identity Center profile that refers to an
+// [sso-session] section and exchanges the cached SSO token (from "aws sso
+// login") for role credentials. The token is refreshed like the AWS CLI
+// does.
+func SSOCredentials(profile string) Credentials {
+	return func(ctx context.Context, base aws.Config) (aws.CredentialsProvider, error) {
+		shared, err := config.LoadSharedConfigProfile(ctx, profile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read profile %q: %w", profile, err)
+		}
+		if shared.SSOSession == nil {
+			return nil, fmt.Errorf("profile %q has no sso_session", profile)
+		}
+		if shared.SSOAccountID == "" || shared.SSORoleName == "" {
+			return nil, fmt.Errorf("profile %q needs sso_account_id and sso_role_name", profile)
+		}
+
+		cachePath, err := ssocreds.StandardCachedTokenFilepath(shared.SSOSession.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		ssoCfg := base.Copy()
+		ssoCfg.Region = shared.SSOSession.SSORegion
+		tokens := ssocreds.NewSSOTokenProvider(ssooidc.NewFromConfig(ssoCfg), cachePath)
+
+		return ssocreds.New(sso.NewFromConfig(ssoCfg), shared.SSOAccountID, shared.SSORoleName,
+			shared.SSOSession.SSOStartURL, func(o *ssocreds.Options) {
+				o.SSOTokenProvider = tokens
+			}), nil
+	}
+}
+
+// LoadWithOptions is Load with the credentials replaced by creds, for
+// embedding the client where credentials don't come from the environment.
+// A nil creds keeps the default chain.
+func LoadWithOptions(ctx context.Context, opts Options, creds Credentials) (aws.Config, error) {
+	cfg, err := Load(ctx, opts)
+	if err != nil || creds == nil {
+		return cfg, err
+	}
+
+	provider, err := creds(ctx, cfg)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+
+	return cfg, nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileEnv names the environment variable that overrides DefaultFilePath.
+const FileEnv = "S3_CLIENT_CONFIG"
+
+// File holds flag defaults read from a config file. Top-level keys apply to
+// every command that has a flag of that name; keys under a command name
+// apply to that command only and win over top-level ones:
+//
+//	region: eu-west-1
+//	profile: prod
+//	download:
+//	  concurrency: 8
+//	  chunk-size: 25
+//	upload:
+//	  part-size: 50
+//	  guess-content-type: none
+//
+// Only this flat subset of YAML is understood: "key: value" pairs, one level
+// of indented sections, optional quotes and # comments.
+type File struct {
+	Defaults map[string]string
+	Commands map[string]map[string]string
+}
+
+// DefaultFilePath returns $S3_CLIENT_CONFIG, or ~/.s3-client.yaml.
+func DefaultFilePath() string {
+	if p := os.Getenv(FileEnv); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".s3-client.yaml")
+}
+
+// LoadFile reads the config file at path. A missing file is not an error
+// and yields nil.
+func LoadFile(path string) (*File, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file := &File{
+		Defaults: make(map[string]string),
+		Commands: make(map[string]map[string]string),
+	}
+	var section map[string]string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := stripComment(scanner.Text())
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected \"key: value\"", path, n)
+		}
+		key, value = strings.TrimSpace(key), unquote(strings.TrimSpace(value))
+		indented := line[0] == ' ' || line[0] == '\t'
+
+		switch {
+		case indented && section == nil:
+			return nil, fmt.Errorf("%s:%d: indented key %q outside a command section", path, n, key)
+		case indented:
+			section[key] = value
+		case value == "":
+			section = make(map[string]string)
+			file.Commands[key] = section
+		default:
+			section = nil
+			file.Defaults[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func stripComment(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return ""
+	}
+	if i := strings.Index(line, " #"); i != -1 {
+		return line[:i]
+	}
+	return line
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// resettable is implemented by flag values that collect every use of a
+// repeatable flag. Reset empties the collection.
+type resettable interface {
+	flag.Value
+	Reset()
+}
+
+// fileDefault wraps a repeatable flag preset from the config file, so the
+// first use on the command line replaces the file's values instead of adding
+// to them.
+type fileDefault struct {
+	resettable
+	replaced bool
+}
+
+func (d *fileDefault) Set(s string) error {
+	if !d.replaced {
+		d.Reset()
+		d.replaced = true
+	}
+	return d.resettable.Set(s)
+}
+
+// Apply presets every flag of flags named in the file, top-level keys first
+// and then the section named after the flag set. It must run before
+// flags.Parse so the command line still overrides the file. The values
+// become the flags' defaults rather than being set through flags.Set, so
+// flags.Visit still reports only what was given on the command line. Keys
+// the command has no flag for are ignored.
+func (f *File) Apply(flags *flag.FlagSet) error {
+	if f == nil {
+		return nil
+	}
+	for _, values := range []map[string]string{f.Defaults, f.Commands[flags.Name()]} {
+		for name, value := range values {
+			fl := flags.Lookup(name)
+			if fl == nil {
+				continue
+			}
+			if d, ok := fl.Value.(*fileDefault); ok {
+				// The command's section replaces a top-level value.
+				d.Reset()
+				fl.Value = d.resettable
+			}
+			if err := fl.Value.Set(value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			fl.DefValue = fl.Value.String()
+			if r, ok := fl.Value.(resettable); ok {
+				fl.Value = &fileDefault{resettable: r}
+			}
+		}
+	}
+	return nil
+}
+
+// applyFile loads DefaultFilePath and applies it to flags.
+func applyFile(flags *flag.FlagSet) error {
+	path := DefaultFilePath()
+	f, err := LoadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if err := f.Apply(flags); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// listFlag is a repeatable flag like upload's -include.
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(s string) error { *l = append(*l, s); return nil }
+func (l *listFlag) Reset()             { *l = nil }
+
+func loadTestFile(t *testing.T, content string) *File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFileApplyPresetsDefaults(t *testing.T) {
+	f := loadTestFile(t, `
+region: eu-west-1   # top level
+concurrency: 2
+download:
+  concurrency: 8
+upload:
+  concurrency: 3
+`)
+
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	region := fs.String("region", "", "")
+	concurrency := fs.Int("concurrency", 5, "")
+	if err := f.Apply(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if *region != "eu-west-1" || *concurrency != 8 {
+		t.Errorf("region, concurrency = %q, %d; want eu-west-1, 8", *region, *concurrency)
+	}
+	if got := fs.Lookup("concurrency").DefValue; got != "8" {
+		t.Errorf("DefValue = %q, want 8", got)
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		t.Errorf("flag %s from the config file reported as set on the command line", fl.Name)
+	})
+}
+
+func TestFileApplyCommandLineWins(t *testing.T) {
+	f := loadTestFile(t, "concurrency: 8\n")
+
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	concurrency := fs.Int("concurrency", 5, "")
+	if err := f.Apply(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"-concurrency", "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if *concurrency != 3 {
+		t.Errorf("concurrency = %d, want 3", *concurrency)
+	}
+	var visited []string
+	fs.Visit(func(fl *flag.Flag) { visited = append(visited, fl.Name) })
+	if !slices.Equal(visited, []string{"concurrency"}) {
+		t.Errorf("visited = %v, want [concurrency]", visited)
+	}
+}
+
+func TestFileApplyRepeatableFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"file only", nil, []string{"*.go"}},
+		{"command line replaces file", []string{"-include", "*.md", "-include", "*.txt"}, []string{"*.md", "*.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := loadTestFile(t, "include: '*.log'\nupload:\n  include: '*.go'\n")
+
+			fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+			var include listFlag
+			fs.Var(&include, "include", "")
+			if err := f.Apply(fs); err != nil {
+				t.Fatal(err)
+			}
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(include, tt.want) {
+				t.Errorf("include = %q, want %q", include, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileApplyInvalidValue(t *testing.T) {
+	f := loadTestFile(t, "concurrency: lots\n")
+
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	fs.Int("concurrency", 5, "")
+	if err := f.Apply(fs); err == nil {
+		t.Fatal("Apply() succeeded, want an error for a non-numeric concurrency")
+	}
+}
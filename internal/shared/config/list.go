@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"s3-client/internal/shared/s3ops"
+)
+
+// ListFlags holds the listing tuning flags shared by commands that page
+// through ListObjectsV2.
+type ListFlags struct {
+	PageSize  int
+	PageDelay time.Duration
+}
+
+// AddListFlags registers -page-size and -page-delay on fs. Call it before
+// AddFlags so the config file can preset them too.
+func AddListFlags(fs *flag.FlagSet, lf *ListFlags) {
+	fs.IntVar(&lf.PageSize, "page-size", 0, "Keys per listing request, 1-1000 (default 1000); smaller pages help on throttled buckets")
+	fs.DurationVar(&lf.PageDelay, "page-delay", 0, "Pause between listing requests, e.g. 200ms")
+}
+
+// Validate rejects page sizes S3 would not accept.
+func (lf ListFlags) Validate() error {
+	if lf.PageSize < 0 || lf.PageSize > 1000 {
+		return errors.New("-page-size must be between 1 and 1000")
+	}
+	if lf.PageDelay < 0 {
+		return errors.New("-page-delay must not be negative")
+	}
+	return nil
+}
+
+// Options converts the flags into s3ops listing options.
+func (lf ListFlags) Options() []s3ops.ListOption {
+	var opts []s3ops.ListOption
+	if lf.PageSize > 0 {
+		opts = append(opts, s3ops.WithPageSize(int32(lf.PageSize)))
+	}
+	if lf.PageDelay > 0 {
+		opts = append(opts, s3ops.WithPageDelay(lf.PageDelay))
+	}
+	return opts
+}
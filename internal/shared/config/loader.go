@@ -5,10 +5,19 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
+// defaultEndpointRegion is the signing region used with a custom -endpoint
+// when none is configured.
+const defaultEndpointRegion = "us-east-1"
+
 func Load(ctx context.Context, opts Options) (aws.Config, error) {
+	if err := opts.Validate(); err != nil {
+		return aws.Config{}, err
+	}
+
 	var cfgOpts []func(*config.LoadOptions) error
 
 	if opts.Region != "" {
@@ -19,30 +28,66 @@ func Load(ctx context.Context, opts Options) (aws.Config, error) {
 		cfgOpts = append(cfgOpts, config.WithSharedConfigProfile(opts.Profile))
 	}
 
+	// With a custom -endpoint the resolver below wins, so these only take
+	// effect for the built-in regional endpoints.
+	if opts.UseFIPS {
+		cfgOpts = append(cfgOpts, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+
+	if opts.UseDualStack {
+		cfgOpts = append(cfgOpts, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+
+	usePathStyle := opts.UsePathStyle()
+
+	tlsCfg, err := opts.TLSConfig()
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if tlsCfg != nil {
+		cfgOpts = append(cfgOpts, config.WithHTTPClient(httpClient(tlsCfg)))
+	}
+
 	if opts.Endpoint != "" {
+		// Local servers such as MinIO accept any region for signing, so
+		// don't make -region mandatory just to get past the SDK. An explicit
+		// -region, AWS_REGION or profile region still wins.
+		cfgOpts = append(cfgOpts, config.WithDefaultRegion(defaultEndpointRegion))
 		cfgOpts = append(cfgOpts, config.WithEndpointResolverWithOptions(
 			aws.EndpointResolverWithOptionsFunc(
 				func(service, region string, options ...interface{}) (aws.Endpoint, error) {
 					return aws.Endpoint{
 						URL:               opts.Endpoint,
-						HostnameImmutable: true,
+						HostnameImmutable: usePathStyle,
 					}, nil
 				},
 			),
 		))
 	}
 
-	return config.LoadDefaultConfig(ctx, cfgOpts...)
-}
+	// Applied through ServiceOptions so every s3.NewFromConfig picks it up.
+	cfgOpts = append(cfgOpts, config.WithServiceOptions(func(serviceID string, o any) {
+		if s3Opts, ok := o.(*s3.Options); ok && serviceID == s3.ServiceID {
+			s3Opts.UsePathStyle = usePathStyle
+		}
+	}))
 
-func LoadWithCredentials(ctx context.Context, opts Options, accessKey, secretKey string) (aws.Config, error) {
-	cfg, err := Load(ctx, opts)
+	cfg, err := config.LoadDefaultConfig(ctx, cfgOpts...)
 	if err != nil {
 		return cfg, err
 	}
 
-	creds := credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")
-	cfg.Credentials = creds
+	// Setting the header on the shared config reaches every client and
+	// operation without touching each input struct. S3 honours it on object
+	// reads, writes, copies, deletes, ACLs, multipart calls and listings, and
+	// ignores it elsewhere.
+	if opts.RequestPayer {
+		cfg.APIOptions = append(cfg.APIOptions, smithyhttp.SetHeaderValue("x-amz-request-payer", "requester"))
+	}
 
 	return cfg, nil
 }
+
+func LoadWithCredentials(ctx context.Context, opts Options, accessKey, secretKey string) (aws.Config, error) {
+	return LoadWithOptions(ctx, opts, StaticCredentials(accessKey, secretKey, ""))
+}
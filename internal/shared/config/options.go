@@ -1,19 +1,130 @@
 package config
 
-import "flag"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
 
 type Options struct {
 	Region   string
 	Profile  string
 	Endpoint string
+	Quiet    bool
+	JSON     bool
+
+	// RequestPayer marks every request as accepting requester-pays charges.
+	RequestPayer bool
+
+	// UseFIPS and UseDualStack select the FIPS and IPv6 (dual-stack)
+	// variants of the regional S3 endpoint.
+	UseFIPS      bool
+	UseDualStack bool
+
+	// PathStyle forces path-style (true) or virtual-hosted (false)
+	// addressing. Left nil, path-style is used exactly when Endpoint is set,
+	// which is what MinIO and most S3-compatible servers expect.
+	PathStyle *bool
+
+	// CABundle is a PEM file of extra CA certificates to trust, for
+	// endpoints with private or self-signed certificates.
+	// InsecureSkipTLSVerify turns certificate verification off entirely.
+	CABundle              string
+	InsecureSkipTLSVerify bool
 }
 
+// AddFlags registers the shared connection and output flags on fs. Call it
+// after the command's own flags: it also presets any of fs's flags found in
+// the config file (see File), which the command line then overrides.
 func AddFlags(fs *flag.FlagSet, opts *Options) {
 	fs.StringVar(&opts.Region, "region", "", "AWS region (overrides env/config)")
 	fs.StringVar(&opts.Profile, "profile", "", "AWS credentials/config profile name")
 	fs.StringVar(&opts.Endpoint, "endpoint", "", "S3-compatible endpoint URL (e.g., http://localhost:9000)")
+	fs.BoolVar(&opts.Quiet, "quiet", false, "Suppress progress and informational output; print only errors")
+	fs.BoolVar(&opts.RequestPayer, "request-payer", false, "Accept charges for requester-pays buckets (sends x-amz-request-payer: requester)")
+	fs.BoolVar(&opts.UseFIPS, "use-fips", false, "Use the FIPS 140-2 S3 endpoint for the region")
+	fs.BoolVar(&opts.UseDualStack, "use-dualstack", false, "Use the dual-stack (IPv4/IPv6) S3 endpoint for the region")
+	fs.StringVar(&opts.CABundle, "ca-bundle", "", "PEM file of CA certificates to trust in addition to the system ones (for self-signed endpoints)")
+	fs.BoolVar(&opts.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Do not verify the endpoint's TLS certificate (testing only)")
+	fs.Var(optionalBool{&opts.PathStyle}, "path-style", "Use path-style addressing (default: on with -endpoint, off otherwise)")
+	fs.BoolVar(&opts.JSON, "json", false, "Print a machine-readable JSON result to stdout (human output goes to stderr)")
+
+	if err := applyFile(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// UsePathStyle reports whether S3 requests should use path-style addressing.
+func (o *Options) UsePathStyle() bool {
+	if o.PathStyle != nil {
+		return *o.PathStyle
+	}
+	return o.Endpoint != ""
+}
+
+// optionalBool is a boolean flag that stays nil until it is given, so an
+// explicit -flag=false can be told apart from the default.
+type optionalBool struct {
+	p **bool
+}
+
+func (b optionalBool) String() string {
+	if b.p == nil || *b.p == nil {
+		return ""
+	}
+	return strconv.FormatBool(**b.p)
+}
+
+func (b optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.p = &v
+	return nil
 }
 
+func (b optionalBool) IsBoolFlag() bool { return true }
+
 func (o *Options) IsEmpty() bool {
 	return o.Region == "" && o.Profile == "" && o.Endpoint == ""
 }
+
+// Validate rejects option combinations that contradict each other.
+func (o *Options) Validate() error {
+	if o.UseFIPS && o.Endpoint != "" && !endpointHostContains(o.Endpoint, "fips") {
+		return errors.New("-use-fips cannot be combined with a non-FIPS -endpoint")
+	}
+	if o.UseDualStack && o.Endpoint != "" && !endpointHostContains(o.Endpoint, "dualstack") {
+		return errors.New("-use-dualstack cannot be combined with a non-dual-stack -endpoint")
+	}
+	return nil
+}
+
+// endpointHostContains reports whether the host of endpoint contains word,
+// which is how AWS names its FIPS and dual-stack endpoint variants.
+func endpointHostContains(endpoint, word string) bool {
+	host := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	return strings.Contains(strings.ToLower(host), word)
+}
+
+// Out returns where human-readable messages should be written: stdout by
+// default, stderr when -json reserves stdout for the result, and nowhere
+// when -quiet is set.
+func (o *Options) Out() io.Writer {
+	if o.Quiet {
+		return io.Discard
+	}
+	if o.JSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"no endpoint", Options{UseFIPS: true, UseDualStack: true}, false},
+		{"custom endpoint alone", Options{Endpoint: "http://localhost:9000"}, false},
+		{"fips with fips endpoint", Options{UseFIPS: true, Endpoint: "https://s3-fips.us-east-1.amazonaws.com"}, false},
+		{"fips with other endpoint", Options{UseFIPS: true, Endpoint: "http://localhost:9000"}, true},
+		{"fips only in path", Options{UseFIPS: true, Endpoint: "http://localhost:9000/fips"}, true},
+		{"dualstack with dualstack endpoint", Options{UseDualStack: true, Endpoint: "https://s3.dualstack.us-east-1.amazonaws.com"}, false},
+		{"dualstack with other endpoint", Options{UseDualStack: true, Endpoint: "http://localhost:9000"}, true},
+		{"dualstack with bare host", Options{UseDualStack: true, Endpoint: "S3.DualStack.eu-west-1.amazonaws.com"}, false},
+		{"both with fips dualstack endpoint", Options{UseFIPS: true, UseDualStack: true, Endpoint: "https://s3-fips.dualstack.us-east-1.amazonaws.com"}, false},
+		{"both with fips-only endpoint", Options{UseFIPS: true, UseDualStack: true, Endpoint: "https://s3-fips.us-east-1.amazonaws.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
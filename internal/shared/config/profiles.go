@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// ListProfiles returns the profile names defined in the shared config and
+// credentials files, honouring AWS_CONFIG_FILE and
+// AWS_SHARED_CREDENTIALS_FILE. Missing files are not an error.
+func ListProfiles() ([]string, error) {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = config.DefaultSharedConfigFilename()
+	}
+	credsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credsFile == "" {
+		credsFile = config.DefaultSharedCredentialsFilename()
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range []struct {
+		path     string
+		isConfig bool
+	}{{configFile, true}, {credsFile, false}} {
+		names, err := profileSections(f.path, f.isConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			seen[name] = true
+		}
+	}
+
+	profiles := make([]string, 0, len(seen))
+	for name := range seen {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// profileSections reads the [section] headers of an ini file. In the config
+// file profiles other than default are written "[profile name]", and other
+// section kinds such as [sso-session x] are skipped.
+func profileSections(path string, isConfig bool) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		section := strings.TrimSpace(line[1 : len(line)-1])
+		if isConfig && section != "default" {
+			name, ok := strings.CutPrefix(section, "profile ")
+			if !ok {
+				continue
+			}
+			section = strings.TrimSpace(name)
+		}
+		if section != "" {
+			names = append(names, section)
+		}
+	}
+	return names, scanner.Err()
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+var insecureWarning sync.Once
+
+// TLSConfig returns the TLS settings selected by -ca-bundle and
+// -insecure-skip-tls-verify, or nil when neither is set and the SDK
+// defaults apply.
+func (o *Options) TLSConfig() (*tls.Config, error) {
+	if o.CABundle == "" && !o.InsecureSkipTLSVerify {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.CABundle != "" {
+		pem, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read -ca-bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("-ca-bundle %s contains no PEM certificates", o.CABundle)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.InsecureSkipTLSVerify {
+		insecureWarning.Do(func() {
+			fmt.Fprintln(os.Stderr, "⚠️  WARNING: TLS certificate verification is disabled (-insecure-skip-tls-verify).")
+			fmt.Fprintln(os.Stderr, "⚠️  Anyone on the network path can read or alter this traffic, credentials included.")
+		})
+		cfg.InsecureSkipVerify = true
+	}
+
+	return cfg, nil
+}
+
+// httpClient returns an SDK HTTP client using tlsCfg.
+func httpClient(tlsCfg *tls.Config) *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().WithTransportOptions(func(t *http.Transport) {
+		t.TLSClientConfig = tlsCfg
+	})
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is one JSON line in the transfer log.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Bucket    string    `json:"bucket"`
+	Key       string    `json:"key"`
+	Bytes     int64     `json:"bytes"`
+	Duration  float64   `json:"duration_seconds"`
+	SpeedMBs  float64   `json:"avg_mb_per_sec"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Log appends transfer records to a file as JSON lines. It is safe for
+// concurrent use, and a nil *Log discards everything. Write failures never
+// fail the transfer; the first one is reported on stderr and the rest are
+// dropped silently.
+type Log struct {
+	mu     sync.Mutex
+	f      *os.File
+	enc    *json.Encoder
+	warned bool
+}
+
+// Open opens path for appending. An empty path returns a nil Log.
+func Open(path string) *Log {
+	if path == "" {
+		return nil
+	}
+	l := &Log{}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		l.warn(err)
+		return l
+	}
+	l.f = f
+	l.enc = json.NewEncoder(f)
+	return l
+}
+
+// Transfer records one object transfer that began at start.
+func (l *Log) Transfer(op, bucket, key string, bytes int64, start time.Time, err error) {
+	if l == nil {
+		return
+	}
+
+	elapsed := time.Since(start)
+	r := Record{
+		Time:      time.Now().UTC(),
+		Operation: op,
+		Bucket:    bucket,
+		Key:       key,
+		Bytes:     bytes,
+		Duration:  elapsed.Seconds(),
+	}
+	if elapsed > 0 {
+		r.SpeedMBs = float64(bytes) / 1024 / 1024 / elapsed.Seconds()
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil {
+		return
+	}
+	if err := l.enc.Encode(r); err != nil {
+		l.warn(err)
+	}
+}
+
+func (l *Log) Close() {
+	if l == nil || l.f == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Close(); err != nil {
+		l.warn(err)
+	}
+	l.f, l.enc = nil, nil
+}
+
+func (l *Log) warn(err error) {
+	if l.warned {
+		return
+	}
+	l.warned = true
+	fmt.Fprintf(os.Stderr, "Warning: transfer log not written: %v\n", err)
+}
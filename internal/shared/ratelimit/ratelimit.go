@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// ParseRate parses a byte rate such as "500KB", "10MB", "1G" or "2048".
+// Suffixes are binary (K = 1024) and a trailing "B" or "/s" is optional.
+func ParseRate(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSuffix(v, "/S")
+	v = strings.TrimSuffix(v, "B")
+
+	mult := int64(1)
+	switch {
+	case strings.HasSuffix(v, "K"):
+		mult = 1024
+	case strings.HasSuffix(v, "M"):
+		mult = 1024 * 1024
+	case strings.HasSuffix(v, "G"):
+		mult = 1024 * 1024 * 1024
+	}
+	if mult > 1 {
+		v = v[:len(v)-1]
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q: want a positive number with optional K, M or G suffix", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// New returns a limiter allowing bytesPerSec bytes per second, shared by
+// every reader and writer wrapped with it. A zero rate returns nil, which
+// the wrappers treat as unlimited.
+func New(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+}
+
+type reader struct {
+	ctx context.Context
+	r   io.Reader
+	lim *rate.Limiter
+}
+
+// NewReader throttles reads from r. If r is an io.Seeker the result is too,
+// so SDK request bodies stay rewindable for retries.
+func NewReader(ctx context.Context, r io.Reader, lim *rate.Limiter) io.Reader {
+	if lim == nil {
+		return r
+	}
+	lr := &reader{ctx: ctx, r: r, lim: lim}
+	if s, ok := r.(io.Seeker); ok {
+		return &readSeeker{reader: lr, s: s}
+	}
+	return lr
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if burst := r.lim.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := r.lim.WaitN(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+type readSeeker struct {
+	*reader
+	s io.Seeker
+}
+
+func (r *readSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.s.Seek(offset, whence)
+}
+
+type writer struct {
+	ctx context.Context
+	w   io.Writer
+	lim *rate.Limiter
+}
+
+// NewWriter throttles writes to w.
+func NewWriter(ctx context.Context, w io.Writer, lim *rate.Limiter) io.Writer {
+	if lim == nil {
+		return w
+	}
+	return &writer{ctx: ctx, w: w, lim: lim}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	written := 0
+	burst := w.lim.Burst()
+	for len(p) > 0 {
+		n := len(p)
+		if n > burst {
+			n = burst
+		}
+		if err := w.lim.WaitN(w.ctx, n); err != nil {
+			return written, err
+		}
+		m, err := w.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
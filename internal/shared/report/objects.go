@@ -0,0 +1,48 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"s3-client/internal/shared/s3ops"
+)
+
+// Object is the JSON form of one listing entry, written one per line by
+// WriteObjects.
+type Object struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified,omitempty"`
+	StorageClass string `json:"storage_class,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	IsDir        bool   `json:"is_dir"`
+}
+
+// NewObject converts obj, formatting LastModified as RFC3339 in UTC and
+// dropping the quotes S3 puts around ETags.
+func NewObject(obj s3ops.ObjectInfo) Object {
+	o := Object{
+		Key:          obj.Key,
+		Size:         obj.Size,
+		StorageClass: obj.StorageClass,
+		ETag:         strings.Trim(obj.ETag, `"`),
+		IsDir:        obj.IsDir,
+	}
+	if !obj.LastModified.IsZero() {
+		o.LastModified = obj.LastModified.UTC().Format(time.RFC3339)
+	}
+	return o
+}
+
+// WriteObjects writes objects as newline-delimited JSON.
+func WriteObjects(w io.Writer, objects []s3ops.ObjectInfo) error {
+	enc := json.NewEncoder(w)
+	for _, obj := range objects {
+		if err := enc.Encode(NewObject(obj)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
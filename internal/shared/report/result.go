@@ -0,0 +1,36 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Result is the machine-readable summary printed by commands run with -json.
+type Result struct {
+	Command  string   `json:"command"`
+	Success  bool     `json:"success"`
+	Bucket   string   `json:"bucket,omitempty"`
+	Keys     []string `json:"keys,omitempty"`
+	Bytes    int64    `json:"bytes"`
+	Duration float64  `json:"duration_seconds"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func New(command, bucket string, start time.Time, err error) Result {
+	r := Result{
+		Command:  command,
+		Success:  err == nil,
+		Bucket:   bucket,
+		Duration: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+func Write(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	return enc.Encode(r)
+}
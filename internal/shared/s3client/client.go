@@ -3,27 +3,109 @@ package s3client
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"sync"
+	"time"
 
 	"s3-client/internal/shared/config"
+	"s3-client/internal/shared/s3ops"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
+// FactoryConfig tunes the HTTP transport used by clients. Zero values keep
+// the SDK defaults, so an empty FactoryConfig behaves like NewFactory.
+type FactoryConfig struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	IdleConnTimeout       time.Duration
+	ResponseHeaderTimeout time.Duration
+}
+
+// ForConcurrency returns a FactoryConfig whose idle pool can hold one
+// connection per worker, avoiding handshake churn at high concurrency.
+func ForConcurrency(workers int) FactoryConfig {
+	if workers <= awshttp.DefaultHTTPTransportMaxIdleConnsPerHost {
+		return FactoryConfig{}
+	}
+	return FactoryConfig{
+		MaxIdleConns:        workers * 2,
+		MaxIdleConnsPerHost: workers,
+	}
+}
+
+func (c FactoryConfig) isZero() bool {
+	return c == FactoryConfig{}
+}
+
+// httpClient layers c's settings onto base, keeping any TLS settings it
+// carries from -ca-bundle or -insecure-skip-tls-verify.
+func (c FactoryConfig) httpClient(base aws.HTTPClient) aws.HTTPClient {
+	client, ok := base.(*awshttp.BuildableClient)
+	if !ok {
+		client = awshttp.NewBuildableClient()
+	}
+	return client.
+		WithTransportOptions(func(t *http.Transport) {
+			if c.MaxIdleConns > 0 {
+				t.MaxIdleConns = c.MaxIdleConns
+			}
+			if c.MaxIdleConnsPerHost > 0 {
+				t.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+			}
+			if c.MaxConnsPerHost > 0 {
+				t.MaxConnsPerHost = c.MaxConnsPerHost
+			}
+			if c.TLSHandshakeTimeout > 0 {
+				t.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+			}
+			if c.IdleConnTimeout > 0 {
+				t.IdleConnTimeout = c.IdleConnTimeout
+			}
+			if c.ResponseHeaderTimeout > 0 {
+				t.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+			}
+		}).
+		WithDialerOptions(func(d *net.Dialer) {
+			if c.DialTimeout > 0 {
+				d.Timeout = c.DialTimeout
+			}
+		})
+}
+
+func (c FactoryConfig) apply(o *s3.Options) {
+	if !c.isZero() {
+		o.HTTPClient = c.httpClient(o.HTTPClient)
+	}
+}
+
 type Factory struct {
 	mu      sync.RWMutex
 	clients map[string]*s3.Client
+	regions map[string]string
+	cfg     FactoryConfig
 }
 
 func NewFactory() *Factory {
+	return NewFactoryWithConfig(FactoryConfig{})
+}
+
+func NewFactoryWithConfig(cfg FactoryConfig) *Factory {
 	return &Factory{
 		clients: make(map[string]*s3.Client),
+		regions: make(map[string]string),
+		cfg:     cfg,
 	}
 }
 
 func (f *Factory) cacheKey(opts config.Options) string {
-	return fmt.Sprintf("%s|%s|%s", opts.Profile, opts.Region, opts.Endpoint)
+	return fmt.Sprintf("%s|%s|%s|%t|%t|%t|%t|%s|%t", opts.Profile, opts.Region, opts.Endpoint, opts.RequestPayer, opts.UseFIPS, opts.UseDualStack, opts.UsePathStyle(), opts.CABundle, opts.InsecureSkipTLSVerify)
 }
 
 func (f *Factory) GetClient(ctx context.Context, opts config.Options) (*s3.Client, error) {
@@ -48,16 +130,55 @@ func (f *Factory) GetClient(ctx context.Context, opts config.Options) (*s3.Clien
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	client := s3.NewFromConfig(awsCfg)
+	client := s3.NewFromConfig(awsCfg, f.cfg.apply)
 	f.clients[key] = client
 
 	return client, nil
 }
 
+// GetClientForBucket returns a client whose region matches the bucket's,
+// looking the region up once with GetBucketLocation and caching it.
+func (f *Factory) GetClientForBucket(ctx context.Context, opts config.Options, bucket string) (*s3.Client, error) {
+	region, err := f.BucketRegion(ctx, opts, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	regionOpts := opts
+	regionOpts.Region = region
+	return f.GetClient(ctx, regionOpts)
+}
+
+func (f *Factory) BucketRegion(ctx context.Context, opts config.Options, bucket string) (string, error) {
+	f.mu.RLock()
+	region, ok := f.regions[bucket]
+	f.mu.RUnlock()
+	if ok {
+		return region, nil
+	}
+
+	client, err := f.GetClient(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	region, err = s3ops.GetBucketLocation(ctx, client, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	f.regions[bucket] = region
+	f.mu.Unlock()
+
+	return region, nil
+}
+
 func (f *Factory) ClearCache() {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	f.clients = make(map[string]*s3.Client)
+	f.regions = make(map[string]string)
 }
 
 type ClientOption func(*s3.Options)
@@ -96,7 +217,7 @@ func (f *Factory) GetClientWithOptions(ctx context.Context, opts config.Options,
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+	client := s3.NewFromConfig(awsCfg, f.cfg.apply, func(o *s3.Options) {
 		for _, opt := range clientOpts {
 			opt(o)
 		}
@@ -109,3 +230,7 @@ func (f *Factory) GetClientWithOptions(ctx context.Context, opts config.Options,
 func GetClientFromConfig(ctx context.Context, awsCfg aws.Config) *s3.Client {
 	return s3.NewFromConfig(awsCfg)
 }
+
+func NewClientFromConfig(awsCfg aws.Config, cfg FactoryConfig) *s3.Client {
+	return s3.NewFromConfig(awsCfg, cfg.apply)
+}
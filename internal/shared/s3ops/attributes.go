@@ -0,0 +1,112 @@
+package s3ops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ObjectChecksum is a checksum S3 stored for an object or part. Algorithm is
+// empty when the object was uploaded without one.
+type ObjectChecksum struct {
+	Algorithm string
+	Value     string
+	// Type is FULL_OBJECT or COMPOSITE (a checksum of the part checksums).
+	Type string
+}
+
+type ObjectPartInfo struct {
+	PartNumber int32
+	Size       int64
+	Checksum   ObjectChecksum
+}
+
+// ObjectAttributes is the result of GetObjectAttributes. TotalParts is zero
+// for objects uploaded in a single PUT; Parts is only filled in for
+// multipart objects uploaded with additional checksums.
+type ObjectAttributes struct {
+	Key          string
+	ETag         string
+	Size         int64
+	StorageClass string
+	Checksum     ObjectChecksum
+	TotalParts   int
+	Parts        []ObjectPartInfo
+}
+
+// GetObjectAttributes fetches size, ETag, storage class, checksum and part
+// layout in one call, paging through the part list of large multipart
+// objects. Unlike HeadObject it reports per-part sizes and checksums.
+func GetObjectAttributes(ctx context.Context, client *s3.Client, bucket, key string) (*ObjectAttributes, error) {
+	input := &s3.GetObjectAttributesInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		ObjectAttributes: []types.ObjectAttributes{
+			types.ObjectAttributesEtag,
+			types.ObjectAttributesChecksum,
+			types.ObjectAttributesObjectParts,
+			types.ObjectAttributesStorageClass,
+			types.ObjectAttributesObjectSize,
+		},
+	}
+
+	attrs := &ObjectAttributes{Key: key}
+	for first := true; ; first = false {
+		resp, err := client.GetObjectAttributes(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get object attributes: %w", err)
+		}
+
+		if first {
+			attrs.ETag = aws.ToString(resp.ETag)
+			attrs.Size = aws.ToInt64(resp.ObjectSize)
+			attrs.StorageClass = string(resp.StorageClass)
+			if resp.Checksum != nil {
+				c := resp.Checksum
+				attrs.Checksum = checksumOf(c.ChecksumCRC32, c.ChecksumCRC32C, c.ChecksumCRC64NVME, c.ChecksumSHA1, c.ChecksumSHA256)
+				attrs.Checksum.Type = string(c.ChecksumType)
+			}
+		}
+
+		parts := resp.ObjectParts
+		if parts == nil {
+			break
+		}
+		attrs.TotalParts = int(aws.ToInt32(parts.TotalPartsCount))
+		for _, p := range parts.Parts {
+			attrs.Parts = append(attrs.Parts, ObjectPartInfo{
+				PartNumber: aws.ToInt32(p.PartNumber),
+				Size:       aws.ToInt64(p.Size),
+				Checksum:   checksumOf(p.ChecksumCRC32, p.ChecksumCRC32C, p.ChecksumCRC64NVME, p.ChecksumSHA1, p.ChecksumSHA256),
+			})
+		}
+		if !aws.ToBool(parts.IsTruncated) {
+			break
+		}
+		input.PartNumberMarker = parts.NextPartNumberMarker
+	}
+
+	return attrs, nil
+}
+
+// checksumOf picks whichever of S3's per-algorithm checksum fields is set.
+func checksumOf(crc32, crc32c, crc64nvme, sha1, sha256 *string) ObjectChecksum {
+	for _, c := range []struct {
+		alg   types.ChecksumAlgorithm
+		value *string
+	}{
+		{types.ChecksumAlgorithmCrc32, crc32},
+		{types.ChecksumAlgorithmCrc32c, crc32c},
+		{types.ChecksumAlgorithmCrc64nvme, crc64nvme},
+		{types.ChecksumAlgorithmSha1, sha1},
+		{types.ChecksumAlgorithmSha256, sha256},
+	} {
+		if c.value != nil {
+			return ObjectChecksum{Algorithm: string(c.alg), Value: *c.value}
+		}
+	}
+	return ObjectChecksum{}
+}
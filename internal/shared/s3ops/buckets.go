@@ -85,7 +85,10 @@ func BucketExists(ctx context.Context, client *s3.Client, bucket string) (bool,
 		Bucket: aws.String(bucket),
 	})
 	if err != nil {
-		return false, nil
+		if ClassifyError(err) == KindNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head bucket: %w", err)
 	}
 	return true, nil
 }
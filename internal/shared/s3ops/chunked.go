@@ -0,0 +1,344 @@
+package s3ops
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultChunkSize   = 10 * 1024 * 1024
+	DefaultConcurrency = 5
+)
+
+type ChunkState int32
+
+const (
+	ChunkWaiting ChunkState = iota
+	ChunkDownloading
+	ChunkDone
+	ChunkFailed
+)
+
+// ChunkedDownloader fetches an object as parallel byte-range requests and
+// writes each range at its offset in the destination. Ranges are streamed
+// through a small buffer, so memory use depends on Concurrency but not on
+// ChunkSize.
+//
+// Progress and OnChunk are called from worker goroutines and must be safe
+// for concurrent use.
+type ChunkedDownloader struct {
+	Client      *s3.Client
+	ChunkSize   int64
+	Concurrency int
+	Progress    func(DownloadProgress)
+	OnChunk     func(index int, state ChunkState)
+
+	// SSECustomerKey is required for objects encrypted with SSE-C.
+	SSECustomerKey *SSECustomerKey
+
+	// Limiter, when set, caps the combined rate of all workers.
+	Limiter *rate.Limiter
+
+	// Retries is how many more times a failed chunk is fetched, with
+	// backoff, before the download fails. The chunk reports ChunkFailed
+	// between attempts and ChunkDownloading again when it is retried.
+	// Progress never goes backwards: bytes fetched again by a retry are
+	// not counted twice.
+	Retries int
+
+	// Adaptive starts with few workers and adds more while throughput keeps
+	// improving, up to Concurrency, halving the count when S3 throttles.
+	// Throttled chunks are retried instead of failing the download.
+	Adaptive bool
+
+	// MaxConsecutiveFailures, when positive, stops the whole download with
+	// ErrUnavailable once that many range requests have failed across all
+	// workers without a success in between, instead of letting every
+	// worker exhaust its own retries. Zero disables the check.
+	MaxConsecutiveFailures int
+}
+
+const (
+	adaptiveStart       = 2
+	adaptiveInterval    = 2 * time.Second
+	adaptiveGain        = 1.10
+	adaptiveMaxAttempts = 5
+)
+
+type chunkRange struct {
+	index int
+	start int64
+	end   int64
+}
+
+func (d *ChunkedDownloader) chunkSize() int64 {
+	if d.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return d.ChunkSize
+}
+
+func (d *ChunkedDownloader) concurrency() int {
+	if d.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return d.Concurrency
+}
+
+// NumChunks reports how many range requests Download will issue for an
+// object of the given size.
+func (d *ChunkedDownloader) NumChunks(size int64) int {
+	cs := d.chunkSize()
+	return int((size + cs - 1) / cs)
+}
+
+func (d *ChunkedDownloader) setState(index int, state ChunkState) {
+	if d.OnChunk != nil {
+		d.OnChunk(index, state)
+	}
+}
+
+// DownloadFile heads the object, pre-allocates outputPath and downloads into
+// it. It returns the object size.
+func (d *ChunkedDownloader) DownloadFile(ctx context.Context, bucket, key, outputPath string) (int64, error) {
+	meta, err := HeadObject(ctx, d.Client, bucket, key, WithSSECustomerKey(d.SSECustomerKey))
+	if err != nil {
+		return 0, err
+	}
+	size := meta.Size
+
+	f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+
+	if err := f.Truncate(size); err != nil {
+		return 0, fmt.Errorf("failed to pre-allocate file: %w", err)
+	}
+
+	return size, d.Download(ctx, bucket, key, f, size)
+}
+
+// Download fetches size bytes of the object into w.
+func (d *ChunkedDownloader) Download(parent context.Context, bucket, key string, w io.WriterAt, size int64) error {
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	var failures atomic.Int32
+
+	cs := d.chunkSize()
+	var chunks []chunkRange
+	for i := int64(0); i < size; i += cs {
+		end := i + cs - 1
+		if end >= size {
+			end = size - 1
+		}
+		chunks = append(chunks, chunkRange{index: len(chunks), start: i, end: end})
+	}
+
+	chunkCh := make(chan chunkRange, len(chunks))
+	for _, c := range chunks {
+		chunkCh <- c
+	}
+	close(chunkCh)
+
+	workers := d.concurrency()
+	errCh := make(chan error, workers)
+	var downloaded int64
+	var wg sync.WaitGroup
+
+	// target is how many workers may take chunks; workers with a higher id
+	// wait. Without Adaptive it never changes.
+	target := int32(workers)
+	var throttled atomic.Bool
+	if d.Adaptive {
+		target = int32(min(adaptiveStart, workers))
+		stop := make(chan struct{})
+		defer close(stop)
+		go d.adapt(&target, int32(workers), &downloaded, &throttled, stop)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(id int32) {
+			defer wg.Done()
+			for {
+				if !waitTurn(ctx, id, &target, chunkCh) {
+					return
+				}
+				c, ok := <-chunkCh
+				if !ok {
+					return
+				}
+				d.setState(c.index, ChunkDownloading)
+
+				rangeSpec := RangeDownload{
+					Start:          c.start,
+					End:            c.end,
+					SSECustomerKey: d.SSECustomerKey,
+					Limiter:        d.Limiter,
+				}
+				// counted is how much of the chunk has been reported. A
+				// retry fetches the range again from its start, and only
+				// bytes past counted are new progress.
+				var counted int64
+				var err error
+				for attempt := 1; ; attempt++ {
+					var written int64
+					err = DownloadRangeTo(ctx, d.Client, bucket, key, rangeSpec, w, c.start, func(n int) {
+						written += int64(n)
+						if written <= counted {
+							return
+						}
+						done := atomic.AddInt64(&downloaded, written-counted)
+						counted = written
+						if d.Progress != nil {
+							d.Progress(DownloadProgress{
+								TotalBytes:      size,
+								DownloadedBytes: done,
+							})
+						}
+					})
+					if err == nil {
+						failures.Store(0)
+						break
+					}
+					if ctx.Err() != nil {
+						break
+					}
+					// Throttling means S3 is answering, so it doesn't count
+					// towards declaring it unavailable.
+					if ClassifyError(err) != KindThrottled && d.tripped(failures.Add(1), err, cancel) {
+						break
+					}
+					isThrottle := d.Adaptive && ClassifyError(err) == KindThrottled
+					if isThrottle {
+						throttled.Store(true)
+					}
+					if !d.shouldRetry(err, attempt, isThrottle) {
+						break
+					}
+					d.setState(c.index, ChunkFailed)
+					select {
+					case <-ctx.Done():
+					case <-time.After(time.Second << (attempt - 1)):
+					}
+					d.setState(c.index, ChunkDownloading)
+				}
+				if err != nil {
+					d.setState(c.index, ChunkFailed)
+					err = fmt.Errorf("chunk %d (%d-%d) failed: %w", c.index, c.start, c.end, err)
+					// The download can't complete, so stop the other
+					// workers, including adaptive ones still waiting in
+					// waitTurn for a turn that would never come.
+					cancel(err)
+					errCh <- err
+					return
+				}
+
+				d.setState(c.index, ChunkDone)
+			}
+		}(int32(i))
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	// A failed chunk or the MaxConsecutiveFailures check cancelled ctx; the
+	// other workers' errors are only the resulting cancellations.
+	if parent.Err() == nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tripped reports whether failures consecutive failed requests exceed
+// MaxConsecutiveFailures; the first caller to trip it cancels the download
+// for every worker.
+func (d *ChunkedDownloader) tripped(failures int32, err error, cancel context.CancelCauseFunc) bool {
+	if d.MaxConsecutiveFailures <= 0 || int(failures) < d.MaxConsecutiveFailures {
+		return false
+	}
+	cancel(fmt.Errorf("%w: %d consecutive range requests failed, last: %v", ErrUnavailable, failures, err))
+	return true
+}
+
+// shouldRetry reports whether a chunk that failed on its attempt-th try is
+// worth fetching again. Throttled chunks in adaptive mode get
+// adaptiveMaxAttempts tries even without Retries; errors another attempt
+// cannot fix are never retried.
+func (d *ChunkedDownloader) shouldRetry(err error, attempt int, throttled bool) bool {
+	switch ClassifyError(err) {
+	case KindAccessDenied, KindNotFound, KindWrongRegion, KindPrecondition, KindArchived:
+		return false
+	}
+	if throttled && attempt < adaptiveMaxAttempts {
+		return true
+	}
+	return attempt <= d.Retries
+}
+
+// waitTurn blocks worker id until it is within target. It returns false when
+// the worker should exit instead: ctx is done, or no chunks are left for it
+// to wait for.
+func waitTurn(ctx context.Context, id int32, target *int32, chunks chan chunkRange) bool {
+	for id >= atomic.LoadInt32(target) {
+		if ctx.Err() != nil || len(chunks) == 0 {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// adapt samples throughput every adaptiveInterval. It adds a worker while
+// throughput grows by more than adaptiveGain, keeps the count when it levels
+// off and halves it after throttling.
+func (d *ChunkedDownloader) adapt(target *int32, max int32, downloaded *int64, throttled *atomic.Bool, stop <-chan struct{}) {
+	ticker := time.NewTicker(adaptiveInterval)
+	defer ticker.Stop()
+
+	var last int64
+	var lastRate float64
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		cur := atomic.LoadInt64(downloaded)
+		rate := float64(cur - last)
+		last = cur
+
+		n := atomic.LoadInt32(target)
+		switch {
+		case throttled.Swap(false):
+			n = max32(1, n/2)
+		case rate > lastRate*adaptiveGain && n < max:
+			n++
+		}
+		atomic.StoreInt32(target, n)
+		lastRate = rate
+	}
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,114 @@
+package s3ops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChunkedDownloadAdaptiveStopsWhenWorkersGiveUp(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusForbidden, "AccessDenied")
+	}))
+	// Only the first adaptiveStart workers take chunks at first; once they
+	// give up, the waiting ones must not keep the download alive.
+	d := &ChunkedDownloader{
+		Client:      client,
+		ChunkSize:   4,
+		Concurrency: 5,
+		Adaptive:    true,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Download(t.Context(), "bucket", "key", make(memWriterAt, 64), 64)
+	}()
+
+	select {
+	case err := <-done:
+		if ClassifyError(err) != KindAccessDenied {
+			t.Fatalf("Download() = %v, want an AccessDenied error", err)
+		}
+		if errors.Is(err, ErrUnavailable) {
+			t.Fatalf("Download() = %v, want the chunk error, not ErrUnavailable", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Download() did not return after every active worker failed")
+	}
+}
+
+func TestChunkedDownloadRetriesFailedRange(t *testing.T) {
+	// Chunks are larger than DownloadRangeTo's read buffer so a retried
+	// range is reported in several writes.
+	const chunkSize = 64 * 1024
+	data := make([]byte, 3*chunkSize)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	var failed atomic.Bool
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The second chunk's first response breaks off three quarters of
+		// the way in, after that much has been counted as progress.
+		if r.Header.Get("Range") == fmt.Sprintf("bytes=%d-%d", chunkSize, 2*chunkSize-1) && !failed.Swap(true) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", chunkSize, 2*chunkSize-1, len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(chunkSize))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[chunkSize : chunkSize+chunkSize*3/4])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		serveObject(w, r, data)
+	}))
+
+	var mu sync.Mutex
+	states := map[int][]ChunkState{}
+	var progress []int64
+	d := &ChunkedDownloader{
+		Client:      client,
+		ChunkSize:   chunkSize,
+		Concurrency: 1,
+		Retries:     1,
+		OnChunk: func(index int, state ChunkState) {
+			mu.Lock()
+			defer mu.Unlock()
+			states[index] = append(states[index], state)
+		},
+		Progress: func(p DownloadProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, p.DownloadedBytes)
+		},
+	}
+
+	out := make(memWriterAt, len(data))
+	if err := d.Download(t.Context(), "bucket", "key", out, int64(len(data))); err != nil {
+		t.Fatalf("Download() = %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Fatal("downloaded content differs from the object")
+	}
+
+	want := []ChunkState{ChunkDownloading, ChunkFailed, ChunkDownloading, ChunkDone}
+	if got := states[1]; !slices.Equal(got, want) {
+		t.Errorf("chunk 1 states = %v, want %v", got, want)
+	}
+	for _, i := range []int{0, 2} {
+		if got := states[i]; !slices.Equal(got, []ChunkState{ChunkDownloading, ChunkDone}) {
+			t.Errorf("chunk %d states = %v, want Downloading, Done", i, got)
+		}
+	}
+
+	if !slices.IsSorted(progress) {
+		t.Errorf("progress went backwards: %v", progress)
+	}
+	if last := progress[len(progress)-1]; last != int64(len(data)) {
+		t.Errorf("final progress = %d, want %d", last, len(data))
+	}
+}
@@ -0,0 +1,176 @@
+package s3ops
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DefaultHashWorkers is how many local files CompareChecksums hashes at once.
+const DefaultHashWorkers = 4
+
+// ChecksumPair is a local file and the object it should match.
+type ChecksumPair struct {
+	LocalPath string
+	Remote    ObjectInfo
+}
+
+// ChecksumResult reports whether LocalPath has the same content as Remote.
+// Err is set when the comparison could not be made; Match is then false.
+type ChecksumResult struct {
+	ChecksumPair
+	Match bool
+	Err   error
+}
+
+// CompareChecksums hashes the local files with a pool of workers and
+// compares each against its object's ETag. Files whose size differs from
+// the object are reported as mismatches without being read.
+//
+// Single-part ETags are the object's MD5. Multipart ETags ("<hex>-N") are
+// the MD5 of the part MD5s, so the file is re-hashed part by part using the
+// object's part size. ETags of SSE-KMS and SSE-C objects are not MD5s and
+// always compare as different.
+func CompareChecksums(ctx context.Context, client *s3.Client, bucket string, pairs []ChecksumPair, workers int) []ChecksumResult {
+	if workers <= 0 {
+		workers = DefaultHashWorkers
+	}
+
+	results := make([]ChecksumResult, len(pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				match, err := compareChecksum(ctx, client, bucket, pairs[i])
+				results[i] = ChecksumResult{ChecksumPair: pairs[i], Match: match, Err: err}
+			}
+		}()
+	}
+
+	for i := range pairs {
+		if ctx.Err() != nil {
+			results[i] = ChecksumResult{ChecksumPair: pairs[i], Err: ctx.Err()}
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func compareChecksum(ctx context.Context, client *s3.Client, bucket string, p ChecksumPair) (bool, error) {
+	info, err := os.Stat(p.LocalPath)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != p.Remote.Size {
+		return false, nil
+	}
+
+	etag := strings.Trim(p.Remote.ETag, `"`)
+	digest, suffix, multipart := strings.Cut(etag, "-")
+	if !multipart {
+		sum, err := fileMD5(p.LocalPath)
+		if err != nil {
+			return false, err
+		}
+		return sum == digest, nil
+	}
+
+	parts, err := strconv.Atoi(suffix)
+	if err != nil || parts <= 0 {
+		return false, fmt.Errorf("unrecognized ETag %s", p.Remote.ETag)
+	}
+	partSize, err := objectPartSize(ctx, client, bucket, p.Remote.Key)
+	if err != nil {
+		return false, err
+	}
+	sum, n, err := multipartMD5(p.LocalPath, partSize)
+	if err != nil {
+		return false, err
+	}
+	return n == parts && sum == digest, nil
+}
+
+// objectPartSize returns the size of a multipart object's first part, which
+// every part but the last shares. GetObjectAttributes only lists parts for
+// uploads made with additional checksums, so otherwise part 1 is HEADed.
+func objectPartSize(ctx context.Context, client *s3.Client, bucket, key string) (int64, error) {
+	attrs, err := GetObjectAttributes(ctx, client, bucket, key)
+	if err == nil && len(attrs.Parts) > 0 && attrs.Parts[0].Size > 0 {
+		return attrs.Parts[0].Size, nil
+	}
+
+	resp, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		PartNumber: aws.Int32(1),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get part size of %s: %w", key, err)
+	}
+	return aws.ToInt64(resp.ContentLength), nil
+}
+
+func fileMD5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// multipartMD5 computes the S3 multipart ETag digest of the file split into
+// partSize parts, returning it with the number of parts.
+func multipartMD5(path string, partSize int64) (string, int, error) {
+	if partSize <= 0 {
+		return "", 0, fmt.Errorf("invalid part size %d", partSize)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	var digests []byte
+	parts := 0
+	for {
+		h := md5.New()
+		n, err := io.CopyN(h, f, partSize)
+		if err != nil && err != io.EOF {
+			return "", 0, err
+		}
+		if n == 0 {
+			break
+		}
+		digests = h.Sum(digests)
+		parts++
+		if n < partSize {
+			break
+		}
+	}
+
+	sum := md5.Sum(digests)
+	return hex.EncodeToString(sum[:]), parts, nil
+}
@@ -0,0 +1,46 @@
+package s3ops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Preconditions are the conditional-request headers of a GET or HEAD. Unset
+// fields are not sent.
+type Preconditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+func (p Preconditions) IsZero() bool {
+	return p.IfMatch == "" && p.IfNoneMatch == "" && p.IfModifiedSince == nil && p.IfUnmodifiedSince == nil
+}
+
+// CheckPreconditions sends a HEAD carrying p. When S3 answers 304 or 412 the
+// returned error classifies as KindPrecondition.
+func CheckPreconditions(ctx context.Context, client *s3.Client, bucket, key string, p Preconditions, sse *SSECustomerKey) error {
+	input := &s3.HeadObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		IfModifiedSince:   p.IfModifiedSince,
+		IfUnmodifiedSince: p.IfUnmodifiedSince,
+	}
+	if p.IfMatch != "" {
+		input.IfMatch = aws.String(p.IfMatch)
+	}
+	if p.IfNoneMatch != "" {
+		input.IfNoneMatch = aws.String(p.IfNoneMatch)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = sse.Fields()
+
+	if _, err := client.HeadObject(ctx, input); err != nil {
+		return fmt.Errorf("precondition check: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package s3ops
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// contentTypes maps lower-case file extensions, dot included, to the
+// Content-Type uploads get by default.
+var contentTypes = map[string]string{
+	".html":  "text/html",
+	".htm":   "text/html",
+	".css":   "text/css",
+	".js":    "application/javascript",
+	".mjs":   "application/javascript",
+	".json":  "application/json",
+	".map":   "application/json",
+	".xml":   "application/xml",
+	".txt":   "text/plain",
+	".md":    "text/markdown",
+	".csv":   "text/csv",
+	".yaml":  "application/yaml",
+	".yml":   "application/yaml",
+	".wasm":  "application/wasm",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".png":   "image/png",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+	".avif":  "image/avif",
+	".svg":   "image/svg+xml",
+	".ico":   "image/x-icon",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".ttf":   "font/ttf",
+	".otf":   "font/otf",
+	".mp4":   "video/mp4",
+	".webm":  "video/webm",
+	".mp3":   "audio/mpeg",
+	".wav":   "audio/wav",
+	".ogg":   "audio/ogg",
+	".pdf":   "application/pdf",
+	".zip":   "application/zip",
+	".tar":   "application/x-tar",
+	".gz":    "application/gzip",
+	".tgz":   "application/gzip",
+}
+
+// ContentTypeByExt returns the Content-Type for path's extension, looking in
+// overrides before the built-in table. Keys in overrides must be lower case
+// with the leading dot. It returns "" for extensions neither knows.
+func ContentTypeByExt(path string, overrides map[string]string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return ""
+	}
+	if ct, ok := overrides[ext]; ok {
+		return ct
+	}
+	return contentTypes[ext]
+}
@@ -0,0 +1,56 @@
+package s3ops
+
+import "testing"
+
+func TestContentTypeByExt(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"index.html", "text/html"},
+		{"app.mjs", "application/javascript"},
+		{"app.js.map", "application/json"},
+		{"module.wasm", "application/wasm"},
+		{"photo.webp", "image/webp"},
+		{"photo.avif", "image/avif"},
+		{"font.woff2", "font/woff2"},
+		{"font.otf", "font/otf"},
+		{"clip.webm", "video/webm"},
+		{"song.ogg", "audio/ogg"},
+		{"data.csv", "text/csv"},
+		{"README.md", "text/markdown"},
+		{"config.yml", "application/yaml"},
+		{"dir/PHOTO.JPG", "image/jpeg"},
+		{"archive.tar.gz", "application/gzip"},
+		{"Makefile", ""},
+		{"file.unknown", ""},
+		{"dir.d/noext", ""},
+	}
+	for _, tt := range tests {
+		if got := ContentTypeByExt(tt.path, nil); got != tt.want {
+			t.Errorf("ContentTypeByExt(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestContentTypeByExtOverrides(t *testing.T) {
+	overrides := map[string]string{
+		".json": "application/vnd.api+json",
+		".log":  "text/plain",
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"data.json", "application/vnd.api+json"}, // override wins over the table
+		{"DATA.JSON", "application/vnd.api+json"},
+		{"server.log", "text/plain"}, // override adds an extension
+		{"page.html", "text/html"},   // table still applies otherwise
+		{"noext", ""},
+	}
+	for _, tt := range tests {
+		if got := ContentTypeByExt(tt.path, overrides); got != tt.want {
+			t.Errorf("ContentTypeByExt(%q, overrides) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package s3ops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// MaxCopyObjectSize is the largest source a single CopyObject call
+	// accepts.
+	MaxCopyObjectSize = 5 * 1024 * 1024 * 1024
+
+	copyPartSize    = 512 * 1024 * 1024
+	copyMaxParts    = 10000
+	copyConcurrency = 8
+)
+
+// CopyLargeObject copies sourceBucket/sourceKey to destBucket/destKey,
+// switching from CopyObject to a multipart copy (parallel UploadPartCopy
+// calls) when the source exceeds MaxCopyObjectSize.
+func CopyLargeObject(ctx context.Context, client *s3.Client, sourceBucket, sourceKey, destBucket, destKey string, opts CopyOptions) error {
+	src, err := HeadObject(ctx, client, sourceBucket, sourceKey)
+	if err != nil {
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+
+	multipart := src.Size > MaxCopyObjectSize
+	keep := readPreserved(ctx, client, sourceBucket, sourceKey, multipart, opts)
+
+	if !multipart {
+		if err := copyObjectSingle(ctx, client, sourceBucket, sourceKey, destBucket, destKey, opts); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(CopyProgress{PartsDone: 1, TotalParts: 1, CopiedBytes: src.Size, TotalBytes: src.Size})
+		}
+	} else if err := copyMultipart(ctx, client, sourceBucket, sourceKey, destBucket, destKey, src, opts); err != nil {
+		return err
+	}
+
+	if err := keep.apply(ctx, client, destBucket, destKey); err != nil {
+		return fmt.Errorf("copied s3://%s/%s, but %w", destBucket, destKey, err)
+	}
+	return nil
+}
+
+// preserved holds the source attributes a copy would otherwise drop.
+type preserved struct {
+	acl  *types.AccessControlPolicy
+	tags map[string]string
+}
+
+// readPreserved reads what opts asks to carry over. Tags are only read for
+// a multipart copy, since CopyObject copies them itself. Preserving is best
+// effort: a caller allowed to copy may still lack s3:GetObjectAcl or
+// s3:GetObjectTagging, so a failed read is passed to opts.Warn and the copy
+// goes ahead without that attribute.
+func readPreserved(ctx context.Context, client *s3.Client, bucket, key string, multipart bool, opts CopyOptions) preserved {
+	var p preserved
+	if opts.PreserveACL {
+		acl, err := GetObjectACL(ctx, client, bucket, key)
+		switch {
+		case err != nil:
+			opts.warn(fmt.Errorf("not preserving the ACL of s3://%s/%s: %w", bucket, key, err))
+		case !isOwnerOnlyACL(acl):
+			// An owner-only ACL is what the copy gets anyway, and the
+			// only one a bucket with ACLs disabled reports.
+			p.acl = acl
+		}
+	}
+	if opts.PreserveTags && multipart {
+		tags, err := GetObjectTagging(ctx, client, bucket, key)
+		if err != nil {
+			opts.warn(fmt.Errorf("not preserving the tags of s3://%s/%s: %w", bucket, key, err))
+		}
+		p.tags = tags
+	}
+	return p
+}
+
+func (p preserved) apply(ctx context.Context, client *s3.Client, bucket, key string) error {
+	if p.acl != nil {
+		if err := PutObjectACLPolicy(ctx, client, bucket, key, p.acl); err != nil {
+			return fmt.Errorf("restoring its ACL failed: %w", err)
+		}
+	}
+	if len(p.tags) > 0 {
+		if err := PutObjectTagging(ctx, client, bucket, key, p.tags); err != nil {
+			return fmt.Errorf("restoring its tags failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func isOwnerOnlyACL(acl *types.AccessControlPolicy) bool {
+	if len(acl.Grants) != 1 || acl.Owner == nil {
+		return false
+	}
+	g := acl.Grants[0]
+	return g.Permission == types.PermissionFullControl && g.Grantee != nil &&
+		g.Grantee.Type == types.TypeCanonicalUser && aws.ToString(g.Grantee.ID) == aws.ToString(acl.Owner.ID)
+}
+
+// copyPartRanges splits size bytes into inclusive [start, end] ranges of at
+// least copyPartSize, growing the part size to stay within copyMaxParts.
+func copyPartRanges(size int64) [][2]int64 {
+	partSize := int64(copyPartSize)
+	if need := (size + copyMaxParts - 1) / copyMaxParts; need > partSize {
+		partSize = need
+	}
+
+	var ranges [][2]int64
+	for start := int64(0); start < size; start += partSize {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		ranges = append(ranges, [2]int64{start, end})
+	}
+	return ranges
+}
+
+func copyMultipart(ctx context.Context, client *s3.Client, sourceBucket, sourceKey, destBucket, destKey string, src *ObjectMetadata, opts CopyOptions) error {
+	// Unlike CopyObject, a multipart upload starts with no metadata, so the
+	// source's is carried over explicitly unless it is being replaced.
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(destBucket),
+		Key:                aws.String(destKey),
+		Metadata:           src.Metadata,
+		ContentType:        optionalString(src.ContentType),
+		CacheControl:       optionalString(src.CacheControl),
+		ContentDisposition: optionalString(src.ContentDisposition),
+		ContentEncoding:    optionalString(src.ContentEncoding),
+		ContentLanguage:    optionalString(src.ContentLanguage),
+		StorageClass:       opts.StorageClass,
+	}
+	if opts.MetadataDirective == types.MetadataDirectiveReplace {
+		createInput.Metadata = opts.Metadata
+		createInput.ContentType = optionalString(opts.ContentType)
+		createInput.CacheControl = optionalString(opts.CacheControl)
+		createInput.ContentDisposition = optionalString(opts.ContentDisposition)
+		createInput.ContentEncoding = optionalString(opts.ContentEncoding)
+		createInput.ContentLanguage = optionalString(opts.ContentLanguage)
+	}
+
+	createResp, err := client.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return fmt.Errorf("failed to start multipart copy: %w", err)
+	}
+	uploadID := createResp.UploadId
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ranges := copyPartRanges(src.Size)
+	parts := make([]types.CompletedPart, 0, len(ranges))
+	var mu sync.Mutex
+	var firstErr error
+	var copied int64
+
+	sem := make(chan struct{}, copyConcurrency)
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(partNumber int32, start, end int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+				Bucket:          aws.String(destBucket),
+				Key:             aws.String(destKey),
+				UploadId:        uploadID,
+				PartNumber:      aws.Int32(partNumber),
+				CopySource:      aws.String(sourceBucket + "/" + sourceKey),
+				CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to copy part %d: %w", partNumber, err)
+					cancel()
+				}
+				return
+			}
+			parts = append(parts, types.CompletedPart{
+				ETag:       resp.CopyPartResult.ETag,
+				PartNumber: aws.Int32(partNumber),
+			})
+			copied += end - start + 1
+			if opts.Progress != nil {
+				opts.Progress(CopyProgress{
+					PartsDone:   len(parts),
+					TotalParts:  len(ranges),
+					CopiedBytes: copied,
+					TotalBytes:  src.Size,
+				})
+			}
+		}(int32(i+1), r[0], r[1])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		AbortMultipartUpload(ctx, client, destBucket, destKey, uploadID)
+		return firstErr
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+	})
+
+	_, err = CompleteMultipartUpload(ctx, client, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(destBucket),
+		Key:             aws.String(destKey),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	}, src.Size)
+	if err != nil {
+		AbortMultipartUpload(ctx, client, destBucket, destKey, uploadID)
+		return fmt.Errorf("failed to complete multipart copy: %w", err)
+	}
+
+	return nil
+}
+
+// optionalString is aws.String, except that "" leaves the field unset.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
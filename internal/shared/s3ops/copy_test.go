@@ -0,0 +1,168 @@
+package s3ops
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// copyServer fakes the S3 calls CopyLargeObject makes for a source of size
+// bytes and records which copy API was used.
+type copyServer struct {
+	size int64
+
+	// denyAttrs makes the ACL and tagging reads fail with AccessDenied.
+	denyAttrs bool
+
+	mu          sync.Mutex
+	copyObjects int
+	partRanges  []string
+	completed   int
+	attrCalls   []string
+}
+
+func (s *copyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	if q.Has("acl") || q.Has("tagging") {
+		name := "acl"
+		if q.Has("tagging") {
+			name = "tagging"
+		}
+		s.attrCalls = append(s.attrCalls, r.Method+" "+name)
+		switch {
+		case r.Method == http.MethodGet && s.denyAttrs:
+			writeError(w, http.StatusForbidden, "AccessDenied")
+		case r.Method == http.MethodGet && name == "acl":
+			fmt.Fprint(w, `<AccessControlPolicy><Owner><ID>owner</ID></Owner><AccessControlList>`+
+				`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>`+
+				`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>`+
+				`</AccessControlList></AccessControlPolicy>`)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `<Tagging><TagSet><Tag><Key>team</Key><Value>data</Value></Tag></TagSet></Tagging>`)
+		}
+		return
+	}
+	switch {
+	case r.Method == http.MethodHead:
+		w.Header().Set("Content-Length", strconv.FormatInt(s.size, 10))
+		w.Header().Set("ETag", `"source"`)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>dst</Bucket><Key>key</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		s.partRanges = append(s.partRanges, r.Header.Get("X-Amz-Copy-Source-Range"))
+		fmt.Fprintf(w, `<CopyPartResult><ETag>"part-%s"</ETag></CopyPartResult>`, q.Get("partNumber"))
+	case r.Method == http.MethodPut:
+		s.copyObjects++
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"copy"</ETag></CopyObjectResult>`)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		s.completed++
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>dst</Bucket><Key>key</Key><ETag>"done-11"</ETag></CompleteMultipartUploadResult>`)
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.String(), http.StatusBadRequest)
+	}
+}
+
+func TestCopyLargeObjectSizeBoundary(t *testing.T) {
+	t.Run("exactly 5 GiB uses CopyObject", func(t *testing.T) {
+		srv := &copyServer{size: MaxCopyObjectSize}
+		client := newTestClient(t, srv)
+		if err := CopyLargeObject(t.Context(), client, "src", "key", "dst", "key", CopyOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if srv.copyObjects != 1 || len(srv.partRanges) != 0 {
+			t.Errorf("CopyObject calls = %d, UploadPartCopy calls = %d; want 1, 0", srv.copyObjects, len(srv.partRanges))
+		}
+	})
+
+	t.Run("5 GiB + 1 uses UploadPartCopy", func(t *testing.T) {
+		size := int64(MaxCopyObjectSize + 1)
+		srv := &copyServer{size: size}
+		client := newTestClient(t, srv)
+
+		var last CopyProgress
+		opts := CopyOptions{Progress: func(p CopyProgress) { last = p }}
+		if err := CopyLargeObject(t.Context(), client, "src", "key", "dst", "key", opts); err != nil {
+			t.Fatal(err)
+		}
+		if srv.copyObjects != 0 || srv.completed != 1 {
+			t.Errorf("CopyObject calls = %d, completions = %d; want 0, 1", srv.copyObjects, srv.completed)
+		}
+
+		var want []string
+		for _, r := range copyPartRanges(size) {
+			want = append(want, fmt.Sprintf("bytes=%d-%d", r[0], r[1]))
+		}
+		slices.Sort(srv.partRanges)
+		slices.Sort(want)
+		if !slices.Equal(srv.partRanges, want) {
+			t.Errorf("part ranges = %v, want %v", srv.partRanges, want)
+		}
+		if last.CopiedBytes != size || last.PartsDone != len(want) {
+			t.Errorf("last progress = %+v, want %d bytes in %d parts", last, size, len(want))
+		}
+	})
+}
+
+func TestCopyLargeObjectPreserve(t *testing.T) {
+	preserve := CopyOptions{PreserveACL: true, PreserveTags: true}
+	tests := []struct {
+		name      string
+		size      int64
+		denyAttrs bool
+		wantCalls []string
+		wantWarns int
+	}{
+		// CopyObject copies the tags itself, so only the ACL round-trips.
+		{"single copy", 1024, false, []string{"GET acl", "PUT acl"}, 0},
+		{"multipart copy", MaxCopyObjectSize + 1, false, []string{"GET acl", "GET tagging", "PUT acl", "PUT tagging"}, 0},
+		{"single copy without ACL access", 1024, true, []string{"GET acl"}, 1},
+		{"multipart copy without ACL or tagging access", MaxCopyObjectSize + 1, true, []string{"GET acl", "GET tagging"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &copyServer{size: tt.size, denyAttrs: tt.denyAttrs}
+			client := newTestClient(t, srv)
+			var warns []error
+			opts := preserve
+			opts.Warn = func(err error) { warns = append(warns, err) }
+			if err := CopyLargeObject(t.Context(), client, "src", "key", "dst", "key", opts); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(srv.attrCalls, tt.wantCalls) {
+				t.Errorf("ACL/tagging calls = %v, want %v", srv.attrCalls, tt.wantCalls)
+			}
+			if len(warns) != tt.wantWarns {
+				t.Errorf("warnings = %v, want %d", warns, tt.wantWarns)
+			}
+		})
+	}
+}
+
+func TestCopyPartRanges(t *testing.T) {
+	size := int64(MaxCopyObjectSize + 1)
+	ranges := copyPartRanges(size)
+	if len(ranges) != 11 {
+		t.Fatalf("len(copyPartRanges(5 GiB + 1)) = %d, want 11", len(ranges))
+	}
+	var next int64
+	for _, r := range ranges {
+		if r[0] != next || r[1] < r[0] {
+			t.Fatalf("ranges %v are not contiguous from 0", ranges)
+		}
+		next = r[1] + 1
+	}
+	if next != size {
+		t.Errorf("ranges end at %d, want %d", next, size)
+	}
+
+	// Huge objects grow the part size to stay within the part limit.
+	if n := len(copyPartRanges(5 * 1024 * 1024 * 1024 * 1024)); n > copyMaxParts {
+		t.Errorf("5 TiB copies in %d parts, more than %d", n, copyMaxParts)
+	}
+}
@@ -1,7 +1,9 @@
 package s3ops
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 
@@ -11,15 +13,16 @@ import (
 )
 
 type CORSRule struct {
-	AllowedOrigins []string
-	AllowedMethods []string
-	AllowedHeaders []string
-	ExposeHeaders  []string
-	MaxAgeSeconds  *int32
+	AllowedOrigins []string `json:"AllowedOrigins" xml:"AllowedOrigin"`
+	AllowedMethods []string `json:"AllowedMethods" xml:"AllowedMethod"`
+	AllowedHeaders []string `json:"AllowedHeaders,omitempty" xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `json:"ExposeHeaders,omitempty" xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  *int32   `json:"MaxAgeSeconds,omitempty" xml:"MaxAgeSeconds,omitempty"`
 }
 
 type CORSConfiguration struct {
-	Rules []CORSRule `xml:"CORSRule"`
+	XMLName xml.Name   `json:"-" xml:"CORSConfiguration"`
+	Rules   []CORSRule `json:"CORSRules" xml:"CORSRule"`
 }
 
 func GetBucketCors(ctx context.Context, client *s3.Client, bucket string) ([]CORSRule, error) {
@@ -83,9 +86,32 @@ func DeleteBucketCors(ctx context.Context, client *s3.Client, bucket string) err
 	return nil
 }
 
+// ParseCORSConfig accepts either JSON (a bare rule array or an object with a
+// "CORSRules" key, as used by the AWS CLI) or the S3 XML CORSConfiguration
+// document.
 func ParseCORSConfig(data []byte) ([]CORSRule, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("failed to parse CORS config: empty input")
+	}
+
+	switch trimmed[0] {
+	case '[':
+		var rules []CORSRule
+		if err := json.Unmarshal(trimmed, &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse CORS config as JSON: %w", err)
+		}
+		return rules, nil
+	case '{':
+		var config CORSConfiguration
+		if err := json.Unmarshal(trimmed, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse CORS config as JSON: %w", err)
+		}
+		return config.Rules, nil
+	}
+
 	var config CORSConfiguration
-	if err := xml.Unmarshal(data, &config); err != nil {
+	if err := xml.Unmarshal(trimmed, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse CORS config: %w", err)
 	}
 	return config.Rules, nil
@@ -0,0 +1,85 @@
+package s3ops
+
+import (
+	"reflect"
+	"testing"
+)
+
+// corsJSON is the set-cors -cors-json example from the usage text, extended
+// with every rule field.
+const corsJSON = `[{"AllowedOrigins":["*"],"AllowedMethods":["GET"]},
+	{"AllowedOrigins":["https://example.com"],"AllowedMethods":["PUT","POST"],
+	 "AllowedHeaders":["*"],"ExposeHeaders":["ETag"],"MaxAgeSeconds":3000}]`
+
+const corsXML = `<CORSConfiguration>
+  <CORSRule>
+    <AllowedOrigin>*</AllowedOrigin>
+    <AllowedMethod>GET</AllowedMethod>
+  </CORSRule>
+  <CORSRule>
+    <AllowedOrigin>https://example.com</AllowedOrigin>
+    <AllowedMethod>PUT</AllowedMethod>
+    <AllowedMethod>POST</AllowedMethod>
+    <AllowedHeader>*</AllowedHeader>
+    <ExposeHeader>ETag</ExposeHeader>
+    <MaxAgeSeconds>3000</MaxAgeSeconds>
+  </CORSRule>
+</CORSConfiguration>`
+
+func TestParseCORSConfigFormatsAgree(t *testing.T) {
+	maxAge := int32(3000)
+	want := []CORSRule{
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+		{
+			AllowedOrigins: []string{"https://example.com"},
+			AllowedMethods: []string{"PUT", "POST"},
+			AllowedHeaders: []string{"*"},
+			ExposeHeaders:  []string{"ETag"},
+			MaxAgeSeconds:  &maxAge,
+		},
+	}
+
+	inputs := map[string]string{
+		"JSON array":          corsJSON,
+		"JSON object":         `{"CORSRules":` + corsJSON + `}`,
+		"XML":                 corsXML,
+		"XML with whitespace": "\n  " + corsXML + "\n",
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseCORSConfig([]byte(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseCORSConfig() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseCORSConfigRoundTripsMarshal(t *testing.T) {
+	rules, err := ParseCORSConfig([]byte(corsJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalCORSConfig(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseCORSConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rules) {
+		t.Errorf("round trip = %+v, want %+v", got, rules)
+	}
+}
+
+func TestParseCORSConfigErrors(t *testing.T) {
+	for _, input := range []string{"", "   ", "[{", `{"CORSRules": 1}`, "<CORSConfiguration>"} {
+		if _, err := ParseCORSConfig([]byte(input)); err == nil {
+			t.Errorf("ParseCORSConfig(%q) succeeded, want an error", input)
+		}
+	}
+}
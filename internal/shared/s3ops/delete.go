@@ -10,70 +10,97 @@ import (
 )
 
 func DeleteObject(ctx context.Context, client *s3.Client, bucket, key string) error {
-	_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
+	return DeleteObjectVersion(ctx, client, bucket, key, "")
+}
+
+// DeleteObjectVersion permanently deletes one version of key. An empty
+// versionID deletes the key itself, which on a versioned bucket only adds
+// a delete marker.
+func DeleteObjectVersion(ctx context.Context, client *s3.Client, bucket, key, versionID string) error {
+	input := &s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	_, err := client.DeleteObject(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to delete object: %w", err)
 	}
 	return nil
 }
 
+// DeleteResult reports the outcome for one key, or for one version of it
+// when VersionID is set.
 type DeleteResult struct {
-	Key     string
-	Deleted bool
-	Error   error
+	Key       string
+	VersionID string
+	Deleted   bool
+	Error     error
 }
 
-func DeleteObjects(ctx context.Context, client *s3.Client, bucket string, keys []string, quiet bool) ([]DeleteResult, error) {
-	if len(keys) == 0 {
-		return nil, nil
-	}
+// ObjectVersionRef names one version of a key. An empty VersionID means the
+// key itself.
+type ObjectVersionRef struct {
+	Key       string
+	VersionID string
+}
 
-	objects := make([]types.ObjectIdentifier, len(keys))
-	for i, key := range keys {
-		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
-	}
+// maxDeleteBatch is the most keys S3 accepts in one DeleteObjects call.
+const maxDeleteBatch = 1000
 
-	resp, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
-		Bucket: aws.String(bucket),
-		Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(quiet)},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to delete objects: %w", err)
-	}
-
-	results := make([]DeleteResult, len(keys))
+// DeleteObjects deletes keys in batches of up to 1000. On a versioned
+// bucket this adds delete markers; use DeleteObjectVersions to remove
+// versions for good.
+func DeleteObjects(ctx context.Context, client *s3.Client, bucket string, keys []string, quiet bool) ([]DeleteResult, error) {
+	refs := make([]ObjectVersionRef, len(keys))
 	for i, key := range keys {
-		results[i] = DeleteResult{Key: key, Deleted: true}
+		refs[i] = ObjectVersionRef{Key: key}
 	}
+	return deleteRefs(ctx, client, bucket, refs, quiet)
+}
 
-	if resp.Deleted != nil {
-		for _, d := range resp.Deleted {
-			key := aws.ToString(d.Key)
-			for i := range results {
-				if results[i].Key == key {
-					results[i].Deleted = true
-					break
-				}
+// DeleteObjectVersions permanently deletes the given versions, in batches
+// of up to 1000. No delete markers are created.
+func DeleteObjectVersions(ctx context.Context, client *s3.Client, bucket string, refs []ObjectVersionRef) ([]DeleteResult, error) {
+	return deleteRefs(ctx, client, bucket, refs, true)
+}
+
+// deleteRefs sends refs to DeleteObjects in batches. Every ref is reported
+// deleted unless S3 returned an error for it; results up to a failed batch
+// are returned along with the error.
+func deleteRefs(ctx context.Context, client *s3.Client, bucket string, refs []ObjectVersionRef, quiet bool) ([]DeleteResult, error) {
+	var results []DeleteResult
+	for start := 0; start < len(refs); start += maxDeleteBatch {
+		batch := refs[start:min(start+maxDeleteBatch, len(refs))]
+
+		objects := make([]types.ObjectIdentifier, len(batch))
+		for i, ref := range batch {
+			objects[i] = types.ObjectIdentifier{Key: aws.String(ref.Key)}
+			if ref.VersionID != "" {
+				objects[i].VersionId = aws.String(ref.VersionID)
 			}
 		}
-	}
 
-	if resp.Errors != nil {
+		resp, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(quiet)},
+		})
+		if err != nil {
+			return results, fmt.Errorf("failed to delete objects: %w", err)
+		}
+
+		failed := make(map[ObjectVersionRef]error, len(resp.Errors))
 		for _, e := range resp.Errors {
-			key := aws.ToString(e.Key)
-			for i := range results {
-				if results[i].Key == key {
-					results[i].Deleted = false
-					results[i].Error = fmt.Errorf("%s: %s", aws.ToString(e.Code), aws.ToString(e.Message))
-					break
-				}
-			}
+			ref := ObjectVersionRef{Key: aws.ToString(e.Key), VersionID: aws.ToString(e.VersionId)}
+			failed[ref] = fmt.Errorf("%s: %s", aws.ToString(e.Code), aws.ToString(e.Message))
+		}
+		for _, ref := range batch {
+			err := failed[ref]
+			results = append(results, DeleteResult{Key: ref.Key, VersionID: ref.VersionID, Deleted: err == nil, Error: err})
 		}
 	}
-
 	return results, nil
 }
 
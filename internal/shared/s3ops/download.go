@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+
+	"s3-client/internal/shared/ratelimit"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/time/rate"
 )
 
 type DownloadProgress struct {
@@ -15,6 +19,9 @@ type DownloadProgress struct {
 	DownloadedBytes int64
 }
 
+// DownloadObject streams an object into outputPath. The file is only
+// created once S3 has answered the GET, so a failed request leaves an
+// existing file untouched.
 func DownloadObject(ctx context.Context, client *s3.Client, bucket, key, outputPath string, progress func(DownloadProgress)) error {
 	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
@@ -31,6 +38,30 @@ func DownloadObject(ctx context.Context, client *s3.Client, bucket, key, outputP
 	}
 	defer f.Close()
 
+	return copyObjectBody(f, resp, progress)
+}
+
+// DownloadObjectTo streams an object sequentially into w. Unlike the chunked
+// range path it needs no seekable destination, so it works for pipes.
+func DownloadObjectTo(ctx context.Context, client *s3.Client, bucket, key string, w io.Writer, progress func(DownloadProgress), opts ...ObjectOption) error {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	newObjectOptions(opts).applyGet(input)
+
+	resp, err := client.GetObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return copyObjectBody(w, resp, progress)
+}
+
+// copyObjectBody writes the body of resp to w, reporting progress after each
+// write.
+func copyObjectBody(w io.Writer, resp *s3.GetObjectOutput, progress func(DownloadProgress)) error {
 	total := aws.ToInt64(resp.ContentLength)
 	downloaded := int64(0)
 	buf := make([]byte, 32*1024)
@@ -38,7 +69,7 @@ func DownloadObject(ctx context.Context, client *s3.Client, bucket, key, outputP
 	for {
 		n, err := resp.Body.Read(buf)
 		if n > 0 {
-			if _, werr := f.Write(buf[:n]); werr != nil {
+			if _, werr := w.Write(buf[:n]); werr != nil {
 				return fmt.Errorf("failed to write: %w", werr)
 			}
 			downloaded += int64(n)
@@ -60,69 +91,186 @@ func DownloadObject(ctx context.Context, client *s3.Client, bucket, key, outputP
 	return nil
 }
 
+// StreamObject copies the object body to w as it arrives, with no progress
+// reporting. A non-empty byteRange is sent as the Range header, e.g.
+// "bytes=0-1023" or "bytes=-512". It returns the number of bytes written.
+func StreamObject(ctx context.Context, client *s3.Client, bucket, key, byteRange string, w io.Writer, opts ...ObjectOption) (int64, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if byteRange != "" {
+		input.Range = aws.String(byteRange)
+	}
+	newObjectOptions(opts).applyGet(input)
+
+	resp, err := client.GetObject(ctx, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to read object: %w", err)
+	}
+	return n, nil
+}
+
 type RangeDownload struct {
 	Start int64
 	End   int64
-}
 
-func DownloadRange(ctx context.Context, client *s3.Client, bucket, key string, rangeSpec RangeDownload) ([]byte, error) {
-	rangeVal := fmt.Sprintf("bytes=%d-%d", rangeSpec.Start, rangeSpec.End)
+	// SSECustomerKey is required when the object uses SSE-C. It is
+	// shorthand for WithSSECustomerKey, which takes precedence.
+	SSECustomerKey *SSECustomerKey
 
-	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
+	// Limiter, when set, caps the read rate. Share one limiter across
+	// concurrent ranges to cap the aggregate.
+	Limiter *rate.Limiter
+}
+
+// rangeInput builds the GetObject request for rangeSpec.
+func rangeInput(bucket, key string, rangeSpec RangeDownload, opts []ObjectOption) *s3.GetObjectInput {
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-		Range:  aws.String(rangeVal),
-	})
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", rangeSpec.Start, rangeSpec.End)),
+	}
+	o := newObjectOptions(append([]ObjectOption{WithSSECustomerKey(rangeSpec.SSECustomerKey)}, opts...))
+	o.applyGet(input)
+	return input
+}
+
+func DownloadRange(ctx context.Context, client *s3.Client, bucket, key string, rangeSpec RangeDownload, opts ...ObjectOption) ([]byte, error) {
+	input := rangeInput(bucket, key, rangeSpec, opts)
+
+	resp, err := client.GetObject(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object range: %w", err)
 	}
 	defer resp.Body.Close()
 
+	want, err := checkRange(rangeSpec, resp.ContentRange, resp.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object: %w", err)
 	}
+	if int64(len(data)) != want {
+		return nil, fmt.Errorf("%w: got %d bytes for bytes=%d-%d, want %d", ErrRangeMismatch, len(data), rangeSpec.Start, rangeSpec.End, want)
+	}
 
 	return data, nil
 }
 
-func GetObjectSize(ctx context.Context, client *s3.Client, bucket, key string) (int64, error) {
-	resp, err := client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
+// DownloadRangeTo streams rangeSpec of the object into w starting at
+// atOffset, calling progress with the size of each write. It stops as soon as
+// ctx is cancelled.
+func DownloadRangeTo(ctx context.Context, client *s3.Client, bucket, key string, rangeSpec RangeDownload, w io.WriterAt, atOffset int64, progress func(n int), opts ...ObjectOption) error {
+	input := rangeInput(bucket, key, rangeSpec, opts)
+
+	resp, err := client.GetObject(ctx, input)
 	if err != nil {
-		return 0, fmt.Errorf("failed to head object: %w", err)
+		return fmt.Errorf("failed to get object range: %w", err)
 	}
+	defer resp.Body.Close()
 
-	return aws.ToInt64(resp.ContentLength), nil
+	want, err := checkRange(rangeSpec, resp.ContentRange, resp.ContentLength)
+	if err != nil {
+		return err
+	}
+
+	body := ratelimit.NewReader(ctx, resp.Body, rangeSpec.Limiter)
+	buf := make([]byte, 32*1024)
+	offset := atOffset
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, werr := w.WriteAt(buf[:n], offset); werr != nil {
+				return fmt.Errorf("failed to write: %w", werr)
+			}
+			offset += int64(n)
+			if progress != nil {
+				progress(n)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read object: %w", err)
+		}
+	}
+	if got := offset - atOffset; got != want {
+		return fmt.Errorf("%w: got %d bytes for bytes=%d-%d, want %d", ErrRangeMismatch, got, rangeSpec.Start, rangeSpec.End, want)
+	}
+
+	return nil
 }
 
-func DownloadToWriter(ctx context.Context, client *s3.Client, bucket, key string, w io.WriterAt, offset int64, length int64, progress func(int64)) error {
-	rangeVal := fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+// checkRange verifies that a ranged GetObject response covers exactly the
+// requested bytes and returns how many body bytes to expect. Some
+// S3-compatible servers ignore Range and send the whole object, which would
+// otherwise be written at the chunk's offset and corrupt the file. A range
+// running past the end of the object is cut short by the server; that is
+// accepted when Content-Range shows the object ends there.
+func checkRange(rangeSpec RangeDownload, contentRange *string, contentLength *int64) (int64, error) {
+	want := rangeSpec.End - rangeSpec.Start + 1
 
-	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
+	if cr := aws.ToString(contentRange); cr != "" {
+		var start, end int64
+		var total string
+		if _, err := fmt.Sscanf(cr, "bytes %d-%d/%s", &start, &end, &total); err != nil {
+			return 0, fmt.Errorf("%w: unparseable Content-Range %q", ErrRangeMismatch, cr)
+		}
+		truncated := end < rangeSpec.End && total == strconv.FormatInt(end+1, 10)
+		if start != rangeSpec.Start || end > rangeSpec.End || (end < rangeSpec.End && !truncated) {
+			return 0, fmt.Errorf("%w: asked for bytes=%d-%d, got %s", ErrRangeMismatch, rangeSpec.Start, rangeSpec.End, cr)
+		}
+		want = end - start + 1
+	} else if rangeSpec.Start != 0 || aws.ToInt64(contentLength) != want {
+		// Without Content-Range the server sent a plain 200; that is only
+		// the requested range if the range was the whole object.
+		return 0, fmt.Errorf("%w: asked for bytes=%d-%d, server ignored the Range header", ErrRangeMismatch, rangeSpec.Start, rangeSpec.End)
+	}
+
+	if contentLength != nil && *contentLength != want {
+		return 0, fmt.Errorf("%w: asked for bytes=%d-%d, Content-Length is %d", ErrRangeMismatch, rangeSpec.Start, rangeSpec.End, *contentLength)
+	}
+	return want, nil
+}
+
+func GetObjectSize(ctx context.Context, client *s3.Client, bucket, key string, opts ...ObjectOption) (int64, error) {
+	input := &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-		Range:  aws.String(rangeVal),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get object range: %w", err)
 	}
-	defer resp.Body.Close()
+	newObjectOptions(opts).applyHead(input)
 
-	data, err := io.ReadAll(resp.Body)
+	resp, err := client.HeadObject(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to read object: %w", err)
+		return 0, fmt.Errorf("failed to head object: %w", err)
 	}
 
-	if _, err := w.WriteAt(data, offset); err != nil {
-		return fmt.Errorf("failed to write: %w", err)
-	}
+	return aws.ToInt64(resp.ContentLength), nil
+}
 
+// DownloadToWriter writes length bytes of the object, starting at offset,
+// to the same offset in w. The range is streamed rather than buffered, so
+// memory use does not grow with length.
+func DownloadToWriter(ctx context.Context, client *s3.Client, bucket, key string, w io.WriterAt, offset int64, length int64, progress func(int64)) error {
+	rangeSpec := RangeDownload{Start: offset, End: offset + length - 1}
+	var report func(int)
 	if progress != nil {
-		progress(int64(len(data)))
+		report = func(n int) { progress(int64(n)) }
 	}
-
-	return nil
+	return DownloadRangeTo(ctx, client, bucket, key, rangeSpec, w, offset, report)
 }
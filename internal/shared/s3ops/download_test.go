@@ -0,0 +1,102 @@
+package s3ops
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestDownloadObjectFailedGetKeepsExistingFile(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "NoSuchKey")
+	}))
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	if err := os.WriteFile(existing, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := DownloadObject(t.Context(), client, "bucket", "key", existing, nil); err == nil {
+		t.Fatal("DownloadObject() succeeded, want an error")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep me" {
+		t.Errorf("existing file now holds %q, want it untouched", data)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	if err := DownloadObject(t.Context(), client, "bucket", "key", missing, nil); err == nil {
+		t.Fatal("DownloadObject() succeeded, want an error")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("failed download left %s behind (stat: %v)", missing, err)
+	}
+}
+
+func TestDownloadObject(t *testing.T) {
+	data := []byte("hello, world")
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveObject(w, r, data)
+	}))
+
+	out := filepath.Join(t.TempDir(), "out")
+	var last DownloadProgress
+	if err := DownloadObject(t.Context(), client, "bucket", "key", out, func(p DownloadProgress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("downloaded %q, want %q", got, data)
+	}
+	if last.DownloadedBytes != int64(len(data)) || last.TotalBytes != int64(len(data)) {
+		t.Errorf("last progress = %+v, want %d of %d", last, len(data), len(data))
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		start, end    int64
+		contentRange  string
+		contentLength *int64
+		want          int64
+		wantErr       bool
+	}{
+		{"exact range", 100, 199, "bytes 100-199/1000", aws.Int64(100), 100, false},
+		{"no Content-Length", 100, 199, "bytes 100-199/1000", nil, 100, false},
+		{"last chunk cut short at end of object", 900, 1099, "bytes 900-999/1000", aws.Int64(100), 100, false},
+		{"cut short before end of object", 100, 199, "bytes 100-149/1000", aws.Int64(50), 0, true},
+		{"cut short with unknown total", 900, 1099, "bytes 900-999/*", aws.Int64(100), 0, true},
+		{"wrong start", 100, 199, "bytes 0-99/1000", aws.Int64(100), 0, true},
+		{"past requested end", 100, 199, "bytes 100-299/1000", aws.Int64(200), 0, true},
+		{"Content-Length disagrees", 100, 199, "bytes 100-199/1000", aws.Int64(1000), 0, true},
+		{"unparseable Content-Range", 100, 199, "items 1-2", aws.Int64(100), 0, true},
+		{"plain 200 for the whole object", 0, 999, "", aws.Int64(1000), 1000, false},
+		{"plain 200 for a later range", 100, 199, "", aws.Int64(1000), 0, true},
+		{"plain 200 larger than the first range", 0, 99, "", aws.Int64(1000), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contentRange *string
+			if tt.contentRange != "" {
+				contentRange = aws.String(tt.contentRange)
+			}
+			got, err := checkRange(RangeDownload{Start: tt.start, End: tt.end}, contentRange, tt.contentLength)
+			if tt.wantErr {
+				if !errors.Is(err, ErrRangeMismatch) {
+					t.Fatalf("checkRange() error = %v, want ErrRangeMismatch", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("checkRange() = %d, %v; want %d, nil", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package s3ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// BucketEncryption is the default server-side encryption applied to new
+// objects in a bucket.
+type BucketEncryption struct {
+	Algorithm        string
+	KMSKeyID         string
+	BucketKeyEnabled bool
+}
+
+// ValidateBucketEncryption checks that algorithm is AES256 or aws:kms and
+// that a KMS key ID is given only, and always, with aws:kms.
+func ValidateBucketEncryption(algorithm, kmsKeyID string) error {
+	switch types.ServerSideEncryption(algorithm) {
+	case types.ServerSideEncryptionAes256:
+		if kmsKeyID != "" {
+			return errors.New("a KMS key ID can only be used with aws:kms")
+		}
+	case types.ServerSideEncryptionAwsKms:
+		if kmsKeyID == "" {
+			return errors.New("aws:kms requires a KMS key ID")
+		}
+	default:
+		return fmt.Errorf("invalid algorithm %q: must be AES256 or aws:kms", algorithm)
+	}
+	return nil
+}
+
+// GetBucketEncryption returns the bucket's default encryption, or nil if none
+// is configured.
+func GetBucketEncryption(ctx context.Context, client *s3.Client, bucket string) (*BucketEncryption, error) {
+	resp, err := client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ServerSideEncryptionConfigurationNotFoundError" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bucket encryption: %w", err)
+	}
+
+	if resp.ServerSideEncryptionConfiguration == nil {
+		return nil, nil
+	}
+	for _, rule := range resp.ServerSideEncryptionConfiguration.Rules {
+		if rule.ApplyServerSideEncryptionByDefault == nil {
+			continue
+		}
+		return &BucketEncryption{
+			Algorithm:        string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm),
+			KMSKeyID:         aws.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID),
+			BucketKeyEnabled: aws.ToBool(rule.BucketKeyEnabled),
+		}, nil
+	}
+	return nil, nil
+}
+
+func PutBucketEncryption(ctx context.Context, client *s3.Client, bucket, algorithm, kmsKeyID string) error {
+	if err := ValidateBucketEncryption(algorithm, kmsKeyID); err != nil {
+		return err
+	}
+
+	def := &types.ServerSideEncryptionByDefault{
+		SSEAlgorithm: types.ServerSideEncryption(algorithm),
+	}
+	if kmsKeyID != "" {
+		def.KMSMasterKeyID = aws.String(kmsKeyID)
+	}
+
+	_, err := client.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{
+				{ApplyServerSideEncryptionByDefault: def},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put bucket encryption: %w", err)
+	}
+	return nil
+}
+
+func DeleteBucketEncryption(ctx context.Context, client *s3.Client, bucket string) error {
+	_, err := client.DeleteBucketEncryption(ctx, &s3.DeleteBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket encryption: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package s3ops
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrUnavailable is returned when so many requests fail in a row that S3
+// (or the network path to it) looks down, rather than one object or range
+// being at fault.
+var ErrUnavailable = errors.New("S3 appears unavailable")
+
+// ErrRangeMismatch is returned when a ranged GetObject response does not
+// contain exactly the requested bytes, as from servers that ignore Range.
+var ErrRangeMismatch = errors.New("server did not honor the requested byte range")
+
+// ErrorKind is a stable classification of S3 errors, independent of how the
+// SDK formats error strings.
+type ErrorKind int
+
+const (
+	KindOther ErrorKind = iota
+	KindAccessDenied
+	KindNotFound
+	KindWrongRegion
+	KindThrottled
+	KindPrecondition
+	KindArchived
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindAccessDenied:
+		return "AccessDenied"
+	case KindNotFound:
+		return "NotFound"
+	case KindWrongRegion:
+		return "WrongRegion"
+	case KindThrottled:
+		return "Throttled"
+	case KindPrecondition:
+		return "Precondition"
+	case KindArchived:
+		return "Archived"
+	default:
+		return "Other"
+	}
+}
+
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return KindOther
+	}
+
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	var noSuchBucket *types.NoSuchBucket
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) || errors.As(err, &noSuchBucket) {
+		return KindNotFound
+	}
+
+	if IsWrongRegion(err) {
+		return KindWrongRegion
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDenied", "Forbidden", "AllAccessDisabled", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+			return KindAccessDenied
+		case "NoSuchKey", "NotFound", "NoSuchBucket":
+			return KindNotFound
+		case "SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException":
+			return KindThrottled
+		case "PreconditionFailed", "NotModified":
+			return KindPrecondition
+		case "InvalidObjectState":
+			return KindArchived
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusForbidden:
+			return KindAccessDenied
+		case http.StatusNotFound:
+			return KindNotFound
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return KindThrottled
+		case http.StatusNotModified, http.StatusPreconditionFailed:
+			return KindPrecondition
+		}
+	}
+
+	return KindOther
+}
+
+// IsWrongRegion reports whether err indicates the request was sent to a
+// region other than the bucket's. HEAD requests carry no error body, so a
+// bare 301 counts as well. A bare 400 has other causes, such as an SSE-C
+// object read without its key, so it only counts when S3 names the bucket's
+// region in x-amz-bucket-region and that is not the region the request was
+// signed for.
+func IsWrongRegion(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "PermanentRedirect", "AuthorizationHeaderMalformed", "IllegalLocationConstraintException":
+			return true
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusMovedPermanently, http.StatusTemporaryRedirect:
+			return true
+		case http.StatusBadRequest:
+			if apiErr != nil && apiErr.ErrorCode() != "BadRequest" {
+				return false
+			}
+			bucketRegion := respErr.Response.Header.Get("X-Amz-Bucket-Region")
+			return bucketRegion != "" && bucketRegion != signingRegion(respErr.Response.Request)
+		}
+	}
+
+	return false
+}
+
+// signingRegion returns the region in the SigV4 credential scope of req's
+// Authorization header, or "" for unsigned requests.
+func signingRegion(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	_, scope, ok := strings.Cut(req.Header.Get("Authorization"), "Credential=")
+	if !ok {
+		return ""
+	}
+	scope, _, _ = strings.Cut(scope, ",")
+	// access-key/date/region/service/aws4_request
+	parts := strings.Split(scope, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
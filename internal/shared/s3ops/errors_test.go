@@ -0,0 +1,103 @@
+package s3ops
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// responseErr builds the error chain the SDK returns for an HTTP error
+// response: status and header describe the response, signedFor is the
+// region in the request's credential scope ("" for unsigned) and apiErr is
+// the decoded error body, if any.
+func responseErr(status int, header http.Header, signedFor string, apiErr error) error {
+	req := &http.Request{Header: http.Header{}}
+	if signedFor != "" {
+		req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250101/"+signedFor+"/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=abc")
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	return &smithy.OperationError{
+		ServiceID:     "S3",
+		OperationName: "HeadObject",
+		Err: &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status, Header: header, Request: req}},
+				Err:      apiErr,
+			},
+		},
+	}
+}
+
+func apiErr(code string) error {
+	return &smithy.GenericAPIError{Code: code, Message: code}
+}
+
+func regionHeader(region string) http.Header {
+	h := http.Header{}
+	h.Set("X-Amz-Bucket-Region", region)
+	return h
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"nil", nil, KindOther},
+		{"plain error", errors.New("boom"), KindOther},
+		{"NoSuchKey type", &types.NoSuchKey{}, KindNotFound},
+		{"NotFound type wrapped", fmt.Errorf("head: %w", &types.NotFound{}), KindNotFound},
+		{"NoSuchBucket type", &types.NoSuchBucket{}, KindNotFound},
+		{"AccessDenied code", responseErr(403, nil, "us-east-1", apiErr("AccessDenied")), KindAccessDenied},
+		{"SignatureDoesNotMatch", responseErr(403, nil, "us-east-1", apiErr("SignatureDoesNotMatch")), KindAccessDenied},
+		{"bare 403", responseErr(403, nil, "us-east-1", nil), KindAccessDenied},
+		{"bare 404", responseErr(404, nil, "us-east-1", nil), KindNotFound},
+		{"SlowDown", responseErr(503, nil, "us-east-1", apiErr("SlowDown")), KindThrottled},
+		{"bare 429", responseErr(429, nil, "us-east-1", nil), KindThrottled},
+		{"bare 503", responseErr(503, nil, "us-east-1", nil), KindThrottled},
+		{"PreconditionFailed", responseErr(412, nil, "us-east-1", apiErr("PreconditionFailed")), KindPrecondition},
+		{"bare 304", responseErr(304, nil, "us-east-1", nil), KindPrecondition},
+		{"InvalidObjectState", responseErr(403, nil, "us-east-1", apiErr("InvalidObjectState")), KindArchived},
+		{"PermanentRedirect", responseErr(301, nil, "us-east-1", apiErr("PermanentRedirect")), KindWrongRegion},
+		{"AuthorizationHeaderMalformed", responseErr(400, nil, "us-east-1", apiErr("AuthorizationHeaderMalformed")), KindWrongRegion},
+		{"bare 301", responseErr(301, nil, "us-east-1", nil), KindWrongRegion},
+		{"bare 307", responseErr(307, nil, "us-east-1", nil), KindWrongRegion},
+		{"400 naming another region", responseErr(400, regionHeader("eu-west-1"), "us-east-1", apiErr("BadRequest")), KindWrongRegion},
+		{"400 naming the signed region", responseErr(400, regionHeader("us-east-1"), "us-east-1", apiErr("BadRequest")), KindOther},
+		{"400 without region header", responseErr(400, nil, "us-east-1", apiErr("BadRequest")), KindOther},
+		{"unsigned 400 naming a region", responseErr(400, regionHeader("eu-west-1"), "", nil), KindWrongRegion},
+		{"400 with other code", responseErr(400, regionHeader("eu-west-1"), "us-east-1", apiErr("InvalidArgument")), KindOther},
+		{"500", responseErr(500, nil, "us-east-1", apiErr("InternalError")), KindOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// An SSE-C object read without its key gets a bare 400 on HEAD, which must
+// not send the caller off to retry in another region.
+func TestClassifyErrorBareBadRequestFromServer(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	_, err := HeadObject(t.Context(), client, "bucket", "key")
+	if err == nil {
+		t.Fatal("HeadObject() succeeded, want an error")
+	}
+	if IsWrongRegion(err) {
+		t.Errorf("IsWrongRegion(%v) = true, want false", err)
+	}
+}
@@ -3,6 +3,7 @@ package s3ops
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -14,25 +15,28 @@ type ObjectMetadata struct {
 	Size                 int64
 	ContentType          string
 	ContentLength        int64
-	LastModified         *string
+	CacheControl         string
+	ContentDisposition   string
+	ContentEncoding      string
+	ContentLanguage      string
+	LastModified         time.Time
 	ETag                 string
 	StorageClass         string
 	Metadata             map[string]string
 	ServerSideEncryption string
+	Restore              string
 }
 
-func HeadObject(ctx context.Context, client *s3.Client, bucket, key string) (*ObjectMetadata, error) {
-	resp, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+func HeadObject(ctx context.Context, client *s3.Client, bucket, key string, opts ...ObjectOption) (*ObjectMetadata, error) {
+	input := &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to head object: %w", err)
 	}
+	newObjectOptions(opts).applyHead(input)
 
-	lastMod := ""
-	if resp.LastModified != nil {
-		lastMod = resp.LastModified.Format("2006-01-02 15:04:05")
+	resp, err := client.HeadObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object: %w", err)
 	}
 
 	meta := &ObjectMetadata{
@@ -41,11 +45,16 @@ func HeadObject(ctx context.Context, client *s3.Client, bucket, key string) (*Ob
 		Size:                 aws.ToInt64(resp.ContentLength),
 		ContentType:          aws.ToString(resp.ContentType),
 		ContentLength:        aws.ToInt64(resp.ContentLength),
-		LastModified:         &lastMod,
+		CacheControl:         aws.ToString(resp.CacheControl),
+		ContentDisposition:   aws.ToString(resp.ContentDisposition),
+		ContentEncoding:      aws.ToString(resp.ContentEncoding),
+		ContentLanguage:      aws.ToString(resp.ContentLanguage),
+		LastModified:         aws.ToTime(resp.LastModified),
 		ETag:                 aws.ToString(resp.ETag),
 		StorageClass:         string(resp.StorageClass),
 		Metadata:             resp.Metadata,
 		ServerSideEncryption: string(resp.ServerSideEncryption),
+		Restore:              aws.ToString(resp.Restore),
 	}
 
 	return meta, nil
@@ -61,7 +70,10 @@ func ObjectExists(ctx context.Context, client *s3.Client, bucket, key string) (b
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return false, nil
+		if ClassifyError(err) == KindNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head object: %w", err)
 	}
 	return true, nil
 }
@@ -0,0 +1,254 @@
+package s3ops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// LifecycleRule is one bucket lifecycle rule. A rule applies to objects
+// under Prefix that carry all of Tags.
+//
+// TransitionDays and TransitionStorageClass are shorthand for a single entry
+// in Transitions and are only read from rule files.
+//
+// Unsupported lists the settings of a rule read from S3 that this type
+// cannot express, such as noncurrent-version actions. Such rules are shown
+// but rejected by PutBucketLifecycle, so that writing back the output of
+// GetBucketLifecycle never silently drops them.
+type LifecycleRule struct {
+	ID                           string                `json:"ID,omitempty"`
+	Prefix                       string                `json:"Prefix"`
+	Tags                         map[string]string     `json:"Tags,omitempty"`
+	Status                       string                `json:"Status,omitempty"`
+	ExpirationDays               *int32                `json:"ExpirationDays,omitempty"`
+	Transitions                  []LifecycleTransition `json:"Transitions,omitempty"`
+	TransitionDays               *int32                `json:"TransitionDays,omitempty"`
+	TransitionStorageClass       string                `json:"TransitionStorageClass,omitempty"`
+	AbortIncompleteMultipartDays *int32                `json:"AbortIncompleteMultipartDays,omitempty"`
+	Unsupported                  []string              `json:"Unsupported,omitempty"`
+}
+
+// LifecycleTransition moves objects to StorageClass Days after creation.
+type LifecycleTransition struct {
+	Days         *int32 `json:"Days"`
+	StorageClass string `json:"StorageClass"`
+}
+
+type LifecycleConfiguration struct {
+	Rules []LifecycleRule `json:"Rules"`
+}
+
+func GetBucketLifecycle(ctx context.Context, client *s3.Client, bucket string) ([]LifecycleRule, error) {
+	resp, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bucket lifecycle: %w", err)
+	}
+
+	rules := make([]LifecycleRule, len(resp.Rules))
+	for i, rule := range resp.Rules {
+		rules[i] = lifecycleRuleFrom(rule)
+	}
+
+	return rules, nil
+}
+
+// lifecycleRuleFrom converts an SDK rule, noting in Unsupported whatever it
+// has to leave out.
+func lifecycleRuleFrom(rule types.LifecycleRule) LifecycleRule {
+	r := LifecycleRule{
+		ID:     aws.ToString(rule.ID),
+		Prefix: aws.ToString(rule.Prefix),
+		Status: string(rule.Status),
+	}
+	unsupported := func(what string) {
+		r.Unsupported = append(r.Unsupported, what)
+	}
+
+	if f := rule.Filter; f != nil {
+		if f.Prefix != nil {
+			r.Prefix = *f.Prefix
+		}
+		if f.Tag != nil {
+			r.Tags = map[string]string{aws.ToString(f.Tag.Key): aws.ToString(f.Tag.Value)}
+		}
+		if f.ObjectSizeGreaterThan != nil || f.ObjectSizeLessThan != nil {
+			unsupported("object size filter")
+		}
+		if and := f.And; and != nil {
+			r.Prefix = aws.ToString(and.Prefix)
+			if len(and.Tags) > 0 {
+				r.Tags = make(map[string]string, len(and.Tags))
+				for _, tag := range and.Tags {
+					r.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+				}
+			}
+			if and.ObjectSizeGreaterThan != nil || and.ObjectSizeLessThan != nil {
+				unsupported("object size filter")
+			}
+		}
+	}
+
+	if e := rule.Expiration; e != nil {
+		r.ExpirationDays = e.Days
+		if e.Date != nil {
+			unsupported("expiration date")
+		}
+		if aws.ToBool(e.ExpiredObjectDeleteMarker) {
+			unsupported("expired object delete marker removal")
+		}
+	}
+	for _, t := range rule.Transitions {
+		if t.Date != nil {
+			unsupported("transition date")
+			continue
+		}
+		r.Transitions = append(r.Transitions, LifecycleTransition{
+			Days:         t.Days,
+			StorageClass: string(t.StorageClass),
+		})
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		r.AbortIncompleteMultipartDays = rule.AbortIncompleteMultipartUpload.DaysAfterInitiation
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		unsupported("noncurrent version expiration")
+	}
+	if len(rule.NoncurrentVersionTransitions) > 0 {
+		unsupported("noncurrent version transitions")
+	}
+	return r
+}
+
+// toSDK converts r for PutBucketLifecycleConfiguration.
+func (r LifecycleRule) toSDK() (types.LifecycleRule, error) {
+	if len(r.Unsupported) > 0 {
+		return types.LifecycleRule{}, fmt.Errorf("rule %q has settings this tool cannot write back (%s); change it with the AWS console or CLI instead",
+			r.ID, strings.Join(r.Unsupported, ", "))
+	}
+
+	status := types.ExpirationStatusEnabled
+	if r.Status != "" {
+		status = types.ExpirationStatus(r.Status)
+	}
+	rule := types.LifecycleRule{
+		Status: status,
+		Filter: lifecycleFilter(r.Prefix, r.Tags),
+	}
+	if r.ID != "" {
+		rule.ID = aws.String(r.ID)
+	}
+	if r.ExpirationDays != nil {
+		rule.Expiration = &types.LifecycleExpiration{Days: r.ExpirationDays}
+	}
+	transitions := r.Transitions
+	if r.TransitionDays != nil {
+		transitions = append(transitions, LifecycleTransition{Days: r.TransitionDays, StorageClass: r.TransitionStorageClass})
+	}
+	for _, t := range transitions {
+		rule.Transitions = append(rule.Transitions, types.Transition{
+			Days:         t.Days,
+			StorageClass: types.TransitionStorageClass(t.StorageClass),
+		})
+	}
+	if r.AbortIncompleteMultipartDays != nil {
+		rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: r.AbortIncompleteMultipartDays,
+		}
+	}
+	return rule, nil
+}
+
+// lifecycleFilter builds the rule filter S3 expects: a bare prefix or tag on
+// its own, and an And block to combine them.
+func lifecycleFilter(prefix string, tags map[string]string) *types.LifecycleRuleFilter {
+	if len(tags) == 0 {
+		return &types.LifecycleRuleFilter{Prefix: aws.String(prefix)}
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s3Tags := make([]types.Tag, len(keys))
+	for i, k := range keys {
+		s3Tags[i] = types.Tag{Key: aws.String(k), Value: aws.String(tags[k])}
+	}
+
+	if prefix == "" && len(s3Tags) == 1 {
+		return &types.LifecycleRuleFilter{Tag: &s3Tags[0]}
+	}
+	and := &types.LifecycleRuleAndOperator{Tags: s3Tags}
+	if prefix != "" {
+		and.Prefix = aws.String(prefix)
+	}
+	return &types.LifecycleRuleFilter{And: and}
+}
+
+func PutBucketLifecycle(ctx context.Context, client *s3.Client, bucket string, rules []LifecycleRule) error {
+	s3Rules := make([]types.LifecycleRule, len(rules))
+	for i, rule := range rules {
+		r, err := rule.toSDK()
+		if err != nil {
+			return err
+		}
+		s3Rules[i] = r
+	}
+
+	_, err := client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+			Rules: s3Rules,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put bucket lifecycle: %w", err)
+	}
+
+	return nil
+}
+
+func DeleteBucketLifecycle(ctx context.Context, client *s3.Client, bucket string) error {
+	_, err := client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// ParseLifecycleConfig accepts either a bare JSON rule array or an object
+// with a "Rules" key.
+func ParseLifecycleConfig(data []byte) ([]LifecycleRule, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var rules []LifecycleRule
+		if err := json.Unmarshal(trimmed, &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse lifecycle config: %w", err)
+		}
+		return rules, nil
+	}
+
+	var config LifecycleConfiguration
+	if err := json.Unmarshal(trimmed, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse lifecycle config: %w", err)
+	}
+	return config.Rules, nil
+}
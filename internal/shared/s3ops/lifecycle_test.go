@@ -0,0 +1,107 @@
+package s3ops
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestLifecycleRuleRoundTrip(t *testing.T) {
+	rules := []types.LifecycleRule{
+		{
+			ID:     aws.String("archive"),
+			Status: types.ExpirationStatusEnabled,
+			Filter: &types.LifecycleRuleFilter{And: &types.LifecycleRuleAndOperator{
+				Prefix: aws.String("logs/"),
+				Tags: []types.Tag{
+					{Key: aws.String("retain"), Value: aws.String("long")},
+					{Key: aws.String("team"), Value: aws.String("ops")},
+				},
+			}},
+			Transitions: []types.Transition{
+				{Days: aws.Int32(30), StorageClass: types.TransitionStorageClassStandardIa},
+				{Days: aws.Int32(365), StorageClass: types.TransitionStorageClassGlacier},
+			},
+			Expiration: &types.LifecycleExpiration{Days: aws.Int32(730)},
+		},
+		{
+			ID:     aws.String("tagged"),
+			Status: types.ExpirationStatusDisabled,
+			Filter: &types.LifecycleRuleFilter{Tag: &types.Tag{Key: aws.String("tmp"), Value: aws.String("yes")}},
+			AbortIncompleteMultipartUpload: &types.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int32(1),
+			},
+		},
+		{
+			ID:     aws.String("prefix"),
+			Status: types.ExpirationStatusEnabled,
+			Filter: &types.LifecycleRuleFilter{Prefix: aws.String("tmp/")},
+		},
+	}
+
+	for _, want := range rules {
+		t.Run(aws.ToString(want.ID), func(t *testing.T) {
+			rule := lifecycleRuleFrom(want)
+
+			// Go through the -show / -file JSON as a user would.
+			data, err := json.Marshal([]LifecycleRule{rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseLifecycleConfig(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parsed[0].toSDK()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLifecycleRuleTransitionShorthand(t *testing.T) {
+	rules, err := ParseLifecycleConfig([]byte(`[{"Prefix":"a/","TransitionDays":30,"TransitionStorageClass":"GLACIER"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rules[0].toSDK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.Transition{{Days: aws.Int32(30), StorageClass: types.TransitionStorageClassGlacier}}
+	if !reflect.DeepEqual(got.Transitions, want) {
+		t.Errorf("Transitions = %+v, want %+v", got.Transitions, want)
+	}
+}
+
+func TestLifecycleRuleUnsupportedIsRefused(t *testing.T) {
+	rule := lifecycleRuleFrom(types.LifecycleRule{
+		ID:     aws.String("versions"),
+		Status: types.ExpirationStatusEnabled,
+		Filter: &types.LifecycleRuleFilter{Prefix: aws.String("")},
+		NoncurrentVersionExpiration: &types.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int32(30),
+		},
+		Transitions: []types.Transition{
+			{Date: aws.Time(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)), StorageClass: types.TransitionStorageClassGlacier},
+		},
+	})
+	want := []string{"transition date", "noncurrent version expiration"}
+	if !reflect.DeepEqual(rule.Unsupported, want) {
+		t.Errorf("Unsupported = %q, want %q", rule.Unsupported, want)
+	}
+
+	_, err := rule.toSDK()
+	if err == nil || !strings.Contains(err.Error(), "noncurrent version expiration") {
+		t.Errorf("toSDK() = %v, want an error naming the unsupported settings", err)
+	}
+}
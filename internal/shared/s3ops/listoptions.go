@@ -0,0 +1,87 @@
+package s3ops
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// defaultListRetries is how many times a failed listing page is retried,
+// on top of the SDK's own retries, before the whole listing gives up.
+const defaultListRetries = 3
+
+// listOptions tunes how the listing functions page through ListObjectsV2.
+type listOptions struct {
+	pageSize  int32
+	pageDelay time.Duration
+	retries   int
+}
+
+// ListOption adjusts a listing call.
+type ListOption func(*listOptions)
+
+// WithPageSize asks for at most n keys per ListObjectsV2 request (S3 caps
+// it at 1000). Zero keeps the S3 default.
+func WithPageSize(n int32) ListOption {
+	return func(o *listOptions) { o.pageSize = n }
+}
+
+// WithPageDelay pauses for d between pages, to stay under request-rate
+// limits on busy shared buckets.
+func WithPageDelay(d time.Duration) ListOption {
+	return func(o *listOptions) { o.pageDelay = d }
+}
+
+// WithListRetries sets how often a failed page is retried with backoff
+// before the listing fails.
+func WithListRetries(n int) ListOption {
+	return func(o *listOptions) { o.retries = n }
+}
+
+func newListOptions(opts []ListOption) listOptions {
+	o := listOptions{retries: defaultListRetries}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// fetch requests one page, retrying with a 1s, 2s, 4s, ... backoff so a
+// burst of 503s mid-listing doesn't throw away the pages already read.
+// Errors another attempt cannot fix are returned at once.
+func (o listOptions) fetch(ctx context.Context, client *s3.Client, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	if o.pageSize > 0 {
+		input.MaxKeys = aws.Int32(o.pageSize)
+	}
+	for attempt := 0; ; attempt++ {
+		page, err := client.ListObjectsV2(ctx, input)
+		if err == nil || attempt >= o.retries || ctx.Err() != nil {
+			return page, err
+		}
+		switch ClassifyError(err) {
+		case KindAccessDenied, KindNotFound, KindWrongRegion:
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(time.Second << attempt):
+		}
+	}
+}
+
+// pause waits out the page delay before the next request.
+func (o listOptions) pause(ctx context.Context) error {
+	if o.pageDelay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(o.pageDelay):
+		return nil
+	}
+}
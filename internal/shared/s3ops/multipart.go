@@ -0,0 +1,80 @@
+package s3ops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MultipartUpload is a multipart upload that was started but neither
+// completed nor aborted. Its parts are billed as storage until it is.
+type MultipartUpload struct {
+	Key          string
+	UploadID     string
+	Initiated    time.Time
+	StorageClass string
+}
+
+// ListMultipartUploads returns the in-progress multipart uploads whose key
+// starts with prefix, ordered by key and then initiation time.
+func ListMultipartUploads(ctx context.Context, client *s3.Client, bucket, prefix string) ([]MultipartUpload, error) {
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	var uploads []MultipartUpload
+	for {
+		page, err := client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list multipart uploads: %w", err)
+		}
+
+		for _, u := range page.Uploads {
+			uploads = append(uploads, MultipartUpload{
+				Key:          aws.ToString(u.Key),
+				UploadID:     aws.ToString(u.UploadId),
+				Initiated:    aws.ToTime(u.Initiated),
+				StorageClass: string(u.StorageClass),
+			})
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		input.KeyMarker = page.NextKeyMarker
+		input.UploadIdMarker = page.NextUploadIdMarker
+	}
+	return uploads, nil
+}
+
+// MultipartUploadSize adds up the parts uploaded so far to an unfinished
+// multipart upload.
+func MultipartUploadSize(ctx context.Context, client *s3.Client, bucket, key, uploadID string) (parts int, size int64, err error) {
+	input := &s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	}
+
+	for {
+		page, err := client.ListParts(ctx, input)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list parts: %w", err)
+		}
+
+		for _, p := range page.Parts {
+			parts++
+			size += aws.ToInt64(p.Size)
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		input.PartNumberMarker = page.NextPartNumberMarker
+	}
+	return parts, size, nil
+}
@@ -0,0 +1,105 @@
+package s3ops
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ObjectOptions holds per-request settings for object reads and writes.
+// Functions accept them as variadic ObjectOption values, so a new setting
+// needs a new option rather than a new signature.
+type ObjectOptions struct {
+	// VersionID selects an object version on reads. Writes ignore it.
+	VersionID string
+	// RequestPayer accepts requester-pays charges for this request only.
+	RequestPayer bool
+	// SSECustomerKey is required for objects encrypted with SSE-C.
+	SSECustomerKey *SSECustomerKey
+	// ChecksumMode asks reads to return the object's stored checksums.
+	ChecksumMode bool
+}
+
+// ObjectOption sets one field of ObjectOptions.
+type ObjectOption func(*ObjectOptions)
+
+func WithVersionID(id string) ObjectOption {
+	return func(o *ObjectOptions) { o.VersionID = id }
+}
+
+func WithRequestPayer() ObjectOption {
+	return func(o *ObjectOptions) { o.RequestPayer = true }
+}
+
+// WithSSECustomerKey sets the SSE-C key. A nil key is allowed and sends no
+// SSE-C headers.
+func WithSSECustomerKey(key *SSECustomerKey) ObjectOption {
+	return func(o *ObjectOptions) { o.SSECustomerKey = key }
+}
+
+func WithChecksumMode() ObjectOption {
+	return func(o *ObjectOptions) { o.ChecksumMode = true }
+}
+
+func newObjectOptions(opts []ObjectOption) ObjectOptions {
+	var o ObjectOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o ObjectOptions) versionID() *string {
+	if o.VersionID == "" {
+		return nil
+	}
+	return aws.String(o.VersionID)
+}
+
+func (o ObjectOptions) requestPayer() types.RequestPayer {
+	if o.RequestPayer {
+		return types.RequestPayerRequester
+	}
+	return ""
+}
+
+func (o ObjectOptions) checksumMode() types.ChecksumMode {
+	if o.ChecksumMode {
+		return types.ChecksumModeEnabled
+	}
+	return ""
+}
+
+func (o ObjectOptions) applyHead(in *s3.HeadObjectInput) {
+	in.VersionId = o.versionID()
+	in.RequestPayer = o.requestPayer()
+	in.ChecksumMode = o.checksumMode()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = o.SSECustomerKey.Fields()
+}
+
+func (o ObjectOptions) applyGet(in *s3.GetObjectInput) {
+	in.VersionId = o.versionID()
+	in.RequestPayer = o.requestPayer()
+	in.ChecksumMode = o.checksumMode()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = o.SSECustomerKey.Fields()
+}
+
+func (o ObjectOptions) applyPut(in *s3.PutObjectInput) {
+	in.RequestPayer = o.requestPayer()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = o.SSECustomerKey.Fields()
+}
+
+func (o ObjectOptions) applyCreateMultipart(in *s3.CreateMultipartUploadInput) {
+	in.RequestPayer = o.requestPayer()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = o.SSECustomerKey.Fields()
+}
+
+func (o ObjectOptions) applyUploadPart(in *s3.UploadPartInput) {
+	in.RequestPayer = o.requestPayer()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = o.SSECustomerKey.Fields()
+}
+
+func (o ObjectOptions) applyComplete(in *s3.CompleteMultipartUploadInput) {
+	in.RequestPayer = o.requestPayer()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = o.SSECustomerKey.Fields()
+}
@@ -0,0 +1,47 @@
+package s3ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func GetBucketPolicy(ctx context.Context, client *s3.Client, bucket string) (string, error) {
+	resp, err := client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchBucketPolicy" {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get bucket policy: %w", err)
+	}
+
+	return aws.ToString(resp.Policy), nil
+}
+
+func PutBucketPolicy(ctx context.Context, client *s3.Client, bucket, policy string) error {
+	_, err := client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(policy),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put bucket policy: %w", err)
+	}
+	return nil
+}
+
+func DeleteBucketPolicy(ctx context.Context, client *s3.Client, bucket string) error {
+	_, err := client.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package s3ops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// RestoreTiers lists the retrieval tiers accepted by RestoreObject.
+var RestoreTiers = []types.Tier{
+	types.TierStandard,
+	types.TierBulk,
+	types.TierExpedited,
+}
+
+// ParseRestoreTier validates s against RestoreTiers, ignoring case.
+func ParseRestoreTier(s string) (types.Tier, error) {
+	names := make([]string, 0, len(RestoreTiers))
+	for _, tier := range RestoreTiers {
+		if strings.EqualFold(string(tier), s) {
+			return tier, nil
+		}
+		names = append(names, string(tier))
+	}
+	return "", fmt.Errorf("invalid restore tier %q: must be one of %s", s, strings.Join(names, ", "))
+}
+
+// RestoreObject asks S3 to make a temporary copy of an archived object
+// available for days days, retrieved with the given tier.
+func RestoreObject(ctx context.Context, client *s3.Client, bucket, key string, days int32, tier string) error {
+	t, err := ParseRestoreTier(tier)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(days),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: t},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore object: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreStatus is the parsed form of the x-amz-restore header.
+type RestoreStatus struct {
+	// Requested is false when no restore has ever been initiated.
+	Requested bool
+	Ongoing   bool
+	Expiry    time.Time
+}
+
+// ParseRestoreStatus parses an x-amz-restore header value such as
+// `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`.
+func ParseRestoreStatus(header string) RestoreStatus {
+	var st RestoreStatus
+	if header == "" {
+		return st
+	}
+	st.Requested = true
+
+	for _, part := range strings.Split(header, `",`) {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch name {
+		case "ongoing-request":
+			st.Ongoing = value == "true"
+		case "expiry-date":
+			if t, err := time.Parse(time.RFC1123, value); err == nil {
+				st.Expiry = t
+			}
+		}
+	}
+
+	return st
+}
@@ -0,0 +1,53 @@
+package s3ops
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// SSECustomerKey is a customer-provided AES256 key for SSE-C. The same key
+// must accompany every read and write of an object encrypted with it.
+type SSECustomerKey struct {
+	key []byte
+}
+
+// ParseSSECustomerKey accepts either a path to a file holding the raw or
+// base64-encoded key, or the base64-encoded key itself. The decoded key must
+// be 32 bytes.
+func ParseSSECustomerKey(s string) (*SSECustomerKey, error) {
+	encoded := s
+	if data, err := os.ReadFile(s); err == nil {
+		if len(data) == 32 {
+			return &SSECustomerKey{key: data}, nil
+		}
+		encoded = string(data)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSE-C key: not a 32-byte key file or base64 string")
+	}
+
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid SSE-C key: AES256 requires 32 bytes, got %d", len(key))
+	}
+	return &SSECustomerKey{key: key}, nil
+}
+
+// Fields returns the SSECustomerAlgorithm, SSECustomerKey and
+// SSECustomerKeyMD5 input values. A nil key yields nil fields, so callers can
+// assign them unconditionally.
+func (k *SSECustomerKey) Fields() (algorithm, key, keyMD5 *string) {
+	if k == nil {
+		return nil, nil, nil
+	}
+	sum := md5.Sum(k.key)
+	return aws.String("AES256"),
+		aws.String(base64.StdEncoding.EncodeToString(k.key)),
+		aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
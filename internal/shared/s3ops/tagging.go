@@ -0,0 +1,145 @@
+package s3ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// S3 tag limits.
+const (
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+	maxBucketTags     = 50
+)
+
+// ValidateBucketTags checks tags against S3's limits: at most 50 tags, keys
+// of 1-128 characters not starting with "aws:", values of up to 256.
+func ValidateBucketTags(tags map[string]string) error {
+	if len(tags) > maxBucketTags {
+		return fmt.Errorf("too many tags: %d (a bucket can have at most %d)", len(tags), maxBucketTags)
+	}
+	for k, v := range tags {
+		if k == "" {
+			return errors.New("tag key must not be empty")
+		}
+		if n := utf8.RuneCountInString(k); n > maxTagKeyLength {
+			return fmt.Errorf("tag key %q is %d characters; the limit is %d", k, n, maxTagKeyLength)
+		}
+		if strings.HasPrefix(strings.ToLower(k), "aws:") {
+			return fmt.Errorf("tag key %q: the aws: prefix is reserved", k)
+		}
+		if n := utf8.RuneCountInString(v); n > maxTagValueLength {
+			return fmt.Errorf("value of tag %q is %d characters; the limit is %d", k, n, maxTagValueLength)
+		}
+	}
+	return nil
+}
+
+// GetBucketTagging returns the bucket's tags; a bucket without tags yields
+// an empty map.
+func GetBucketTagging(ctx context.Context, client *s3.Client, bucket string) (map[string]string, error) {
+	resp, err := client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchTagSet" {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get bucket tagging: %w", err)
+	}
+
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, t := range resp.TagSet {
+		tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+	}
+	return tags, nil
+}
+
+// PutBucketTagging replaces the bucket's whole tag set with tags.
+func PutBucketTagging(ctx context.Context, client *s3.Client, bucket string, tags map[string]string) error {
+	if err := ValidateBucketTags(tags); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]types.Tag, len(keys))
+	for i, k := range keys {
+		tagSet[i] = types.Tag{Key: aws.String(k), Value: aws.String(tags[k])}
+	}
+
+	_, err := client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(bucket),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put bucket tagging: %w", err)
+	}
+	return nil
+}
+
+func DeleteBucketTagging(ctx context.Context, client *s3.Client, bucket string) error {
+	_, err := client.DeleteBucketTagging(ctx, &s3.DeleteBucketTaggingInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket tagging: %w", err)
+	}
+	return nil
+}
+
+// GetObjectTagging returns the object's tags; an untagged object yields an
+// empty map.
+func GetObjectTagging(ctx context.Context, client *s3.Client, bucket, key string) (map[string]string, error) {
+	resp, err := client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object tagging: %w", err)
+	}
+
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, t := range resp.TagSet {
+		tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+	}
+	return tags, nil
+}
+
+// PutObjectTagging replaces the object's whole tag set with tags.
+func PutObjectTagging(ctx context.Context, client *s3.Client, bucket, key string, tags map[string]string) error {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]types.Tag, len(keys))
+	for i, k := range keys {
+		tagSet[i] = types.Tag{Key: aws.String(k), Value: aws.String(tags[k])}
+	}
+
+	_, err := client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object tagging: %w", err)
+	}
+	return nil
+}
@@ -2,15 +2,18 @@ package s3ops
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
 type UploadProgress struct {
@@ -20,7 +23,7 @@ type UploadProgress struct {
 	TotalParts    int
 }
 
-func UploadFile(ctx context.Context, client *s3.Client, localPath, bucket, key string, progress func(UploadProgress)) error {
+func UploadFile(ctx context.Context, client *s3.Client, localPath, bucket, key string, progress func(UploadProgress), opts ...ObjectOption) error {
 	file, err := os.Open(localPath)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
@@ -32,13 +35,16 @@ func UploadFile(ctx context.Context, client *s3.Client, localPath, bucket, key s
 		return fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket:        aws.String(bucket),
 		Key:           aws.String(key),
 		Body:          file,
 		ContentLength: aws.Int64(stat.Size()),
 		ContentType:   aws.String(getContentType(localPath)),
-	})
+	}
+	newObjectOptions(opts).applyPut(input)
+
+	_, err = client.PutObject(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to upload file: %w", err)
 	}
@@ -53,7 +59,7 @@ func UploadFile(ctx context.Context, client *s3.Client, localPath, bucket, key s
 	return nil
 }
 
-func UploadDirectory(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, progress func(UploadProgress)) error {
+func UploadDirectory(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, progress func(UploadProgress), opts ...ObjectOption) error {
 	entries, err := os.ReadDir(localDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
@@ -71,16 +77,16 @@ func UploadDirectory(ctx context.Context, client *s3.Client, localDir, bucket, p
 
 	for _, e := range entries {
 		path := filepath.Join(localDir, e.Name())
-		key := filepath.Join(prefix, e.Name())
+		key := joinKey(prefix, e.Name())
 
 		if e.IsDir() {
 			subDir := filepath.Join(localDir, e.Name())
-			err := uploadDirectoryRecursive(ctx, client, subDir, bucket, key, &uploaded, totalBytes, progress)
+			err := uploadDirectoryRecursive(ctx, client, subDir, bucket, key, &uploaded, totalBytes, progress, opts)
 			if err != nil {
 				return err
 			}
 		} else {
-			err := UploadFile(ctx, client, path, bucket, key, nil)
+			err := UploadFile(ctx, client, path, bucket, key, nil, opts...)
 			if err != nil {
 				return fmt.Errorf("failed to upload %s: %w", e.Name(), err)
 			}
@@ -98,7 +104,16 @@ func UploadDirectory(ctx context.Context, client *s3.Client, localDir, bucket, p
 	return nil
 }
 
-func uploadDirectoryRecursive(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, uploaded *int64, total int64, progress func(UploadProgress)) error {
+// joinKey appends name to prefix with a single "/", regardless of the OS
+// path separator.
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + name
+}
+
+func uploadDirectoryRecursive(ctx context.Context, client *s3.Client, localDir, bucket, prefix string, uploaded *int64, total int64, progress func(UploadProgress), opts []ObjectOption) error {
 	entries, err := os.ReadDir(localDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
@@ -106,15 +121,15 @@ func uploadDirectoryRecursive(ctx context.Context, client *s3.Client, localDir,
 
 	for _, e := range entries {
 		path := filepath.Join(localDir, e.Name())
-		key := filepath.Join(prefix, e.Name())
+		key := joinKey(prefix, e.Name())
 
 		if e.IsDir() {
-			err := uploadDirectoryRecursive(ctx, client, path, bucket, key, uploaded, total, progress)
+			err := uploadDirectoryRecursive(ctx, client, path, bucket, key, uploaded, total, progress, opts)
 			if err != nil {
 				return err
 			}
 		} else {
-			err := UploadFile(ctx, client, path, bucket, key, nil)
+			err := UploadFile(ctx, client, path, bucket, key, nil, opts...)
 			if err != nil {
 				return fmt.Errorf("failed to upload %s: %w", e.Name(), err)
 			}
@@ -188,12 +203,12 @@ func (m *MultipartUploader) UploadPart(ctx context.Context, partNumber int, data
 }
 
 func (m *MultipartUploader) Complete(ctx context.Context) error {
-	_, err := m.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+	_, err := CompleteMultipartUpload(ctx, m.client, &s3.CompleteMultipartUploadInput{
 		Bucket:          aws.String(m.bucket),
 		Key:             aws.String(m.key),
 		UploadId:        m.uploadID,
 		MultipartUpload: &types.CompletedMultipartUpload{Parts: m.completedParts},
-	})
+	}, m.uploadedBytes)
 	if err != nil {
 		return fmt.Errorf("failed to complete multipart upload: %w", err)
 	}
@@ -202,15 +217,81 @@ func (m *MultipartUploader) Complete(ctx context.Context) error {
 }
 
 func (m *MultipartUploader) Abort(ctx context.Context) error {
-	_, err := m.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-		Bucket:   aws.String(m.bucket),
-		Key:      aws.String(m.key),
-		UploadId: m.uploadID,
+	return AbortMultipartUpload(ctx, m.client, m.bucket, m.key, m.uploadID)
+}
+
+// CompleteMultipartUpload completes a multipart upload of expectedSize
+// bytes. If the SDK's retry of a Complete whose first attempt succeeded
+// but timed out gets NoSuchUpload (or NoSuchKey) back, the object is
+// headed instead: if it exists with the expected size and the part count
+// of input in its ETag, the upload is reported as complete rather than
+// failed.
+func CompleteMultipartUpload(ctx context.Context, client *s3.Client, input *s3.CompleteMultipartUploadInput, expectedSize int64) (*s3.CompleteMultipartUploadOutput, error) {
+	resp, err := client.CompleteMultipartUpload(ctx, input)
+	if err == nil || !isNoSuchUpload(err) {
+		return resp, err
+	}
+
+	head, headErr := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:               input.Bucket,
+		Key:                  input.Key,
+		SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+		SSECustomerKey:       input.SSECustomerKey,
+		SSECustomerKeyMD5:    input.SSECustomerKeyMD5,
 	})
-	return err
+	if headErr != nil || aws.ToInt64(head.ContentLength) != expectedSize {
+		return nil, err
+	}
+	// A multipart ETag ends in "-<part count>", which tells this upload
+	// apart from an older object of the same size.
+	if mu := input.MultipartUpload; mu != nil && !strings.HasSuffix(aws.ToString(head.ETag), fmt.Sprintf("-%d\"", len(mu.Parts))) {
+		return nil, err
+	}
+
+	return &s3.CompleteMultipartUploadOutput{
+		Bucket:    input.Bucket,
+		Key:       input.Key,
+		ETag:      head.ETag,
+		VersionId: head.VersionId,
+	}, nil
+}
+
+func isNoSuchUpload(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "NoSuchUpload", "NoSuchKey":
+		return true
+	}
+	return false
 }
 
-func UploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket, key string, partSize int64, progress func(UploadProgress)) error {
+// abortTimeout bounds the cleanup request sent after an upload fails or is
+// interrupted.
+const abortTimeout = 30 * time.Second
+
+// AbortMultipartUpload discards an unfinished multipart upload so its parts
+// stop accruing storage charges. It runs even when ctx is already cancelled,
+// which is the usual case after Ctrl+C.
+func AbortMultipartUpload(ctx context.Context, client *s3.Client, bucket, key string, uploadID *string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+
+	_, err := client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func UploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket, key string, partSize int64, progress func(UploadProgress), opts ...ObjectOption) error {
 	file, err := os.Open(localPath)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
@@ -222,10 +303,14 @@ func UploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket,
 		return fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	resp, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+	o := newObjectOptions(opts)
+	createInput := &s3.CreateMultipartUploadInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
+	}
+	o.applyCreateMultipart(createInput)
+
+	resp, err := client.CreateMultipartUpload(ctx, createInput)
 	if err != nil {
 		return fmt.Errorf("failed to start multipart upload: %w", err)
 	}
@@ -245,27 +330,22 @@ func UploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket,
 
 		buf := make([]byte, chunkSize)
 		if _, err := file.Read(buf); err != nil {
-			client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(bucket),
-				Key:      aws.String(key),
-				UploadId: uploadID,
-			})
+			AbortMultipartUpload(ctx, client, bucket, key, uploadID)
 			return fmt.Errorf("failed to read file: %w", err)
 		}
 
-		uploadResp, err := client.UploadPart(ctx, &s3.UploadPartInput{
+		partInput := &s3.UploadPartInput{
 			Bucket:     aws.String(bucket),
 			Key:        aws.String(key),
 			UploadId:   uploadID,
 			PartNumber: aws.Int32(int32(partNumber)),
 			Body:       strings.NewReader(string(buf)),
-		})
+		}
+		o.applyUploadPart(partInput)
+
+		uploadResp, err := client.UploadPart(ctx, partInput)
 		if err != nil {
-			client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(bucket),
-				Key:      aws.String(key),
-				UploadId: uploadID,
-			})
+			AbortMultipartUpload(ctx, client, bucket, key, uploadID)
 			return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
 		}
 
@@ -286,13 +366,17 @@ func UploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket,
 		}
 	}
 
-	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+	completeInput := &s3.CompleteMultipartUploadInput{
 		Bucket:          aws.String(bucket),
 		Key:             aws.String(key),
 		UploadId:        uploadID,
 		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
-	})
+	}
+	o.applyComplete(completeInput)
+
+	_, err = CompleteMultipartUpload(ctx, client, completeInput, stat.Size())
 	if err != nil {
+		AbortMultipartUpload(ctx, client, bucket, key, uploadID)
 		return fmt.Errorf("failed to complete multipart upload: %w", err)
 	}
 
@@ -300,37 +384,10 @@ func UploadMultipart(ctx context.Context, client *s3.Client, localPath, bucket,
 }
 
 func getContentType(path string) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".html", ".htm":
-		return "text/html"
-	case ".css":
-		return "text/css"
-	case ".js":
-		return "application/javascript"
-	case ".json":
-		return "application/json"
-	case ".xml":
-		return "application/xml"
-	case ".txt":
-		return "text/plain"
-	case ".jpg", ".jpeg":
-		return "image/jpeg"
-	case ".png":
-		return "image/png"
-	case ".gif":
-		return "image/gif"
-	case ".pdf":
-		return "application/pdf"
-	case ".zip":
-		return "application/zip"
-	case ".tar":
-		return "application/x-tar"
-	case ".gz", ".tgz":
-		return "application/gzip"
-	default:
-		return "application/octet-stream"
+	if ct := ContentTypeByExt(path, nil); ct != "" {
+		return ct
 	}
+	return "application/octet-stream"
 }
 
 type ReaderAtSeeker interface {
@@ -338,11 +395,15 @@ type ReaderAtSeeker interface {
 	io.Seeker
 }
 
-func UploadMultipartWithReader(ctx context.Context, client *s3.Client, reader ReaderAtSeeker, size int64, bucket, key string, partSize int64, progress func(UploadProgress)) error {
-	resp, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+func UploadMultipartWithReader(ctx context.Context, client *s3.Client, reader ReaderAtSeeker, size int64, bucket, key string, partSize int64, progress func(UploadProgress), opts ...ObjectOption) error {
+	o := newObjectOptions(opts)
+	createInput := &s3.CreateMultipartUploadInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
+	}
+	o.applyCreateMultipart(createInput)
+
+	resp, err := client.CreateMultipartUpload(ctx, createInput)
 	if err != nil {
 		return fmt.Errorf("failed to start multipart upload: %w", err)
 	}
@@ -363,27 +424,22 @@ func UploadMultipartWithReader(ctx context.Context, client *s3.Client, reader Re
 		buf := make([]byte, chunkSize)
 		_, err := reader.ReadAt(buf, offset)
 		if err != nil && err != io.EOF {
-			client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(bucket),
-				Key:      aws.String(key),
-				UploadId: uploadID,
-			})
+			AbortMultipartUpload(ctx, client, bucket, key, uploadID)
 			return fmt.Errorf("failed to read at offset %d: %w", offset, err)
 		}
 
-		uploadResp, err := client.UploadPart(ctx, &s3.UploadPartInput{
+		partInput := &s3.UploadPartInput{
 			Bucket:     aws.String(bucket),
 			Key:        aws.String(key),
 			UploadId:   uploadID,
 			PartNumber: aws.Int32(int32(partNumber)),
 			Body:       strings.NewReader(string(buf)),
-		})
+		}
+		o.applyUploadPart(partInput)
+
+		uploadResp, err := client.UploadPart(ctx, partInput)
 		if err != nil {
-			client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(bucket),
-				Key:      aws.String(key),
-				UploadId: uploadID,
-			})
+			AbortMultipartUpload(ctx, client, bucket, key, uploadID)
 			return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
 		}
 
@@ -404,13 +460,17 @@ func UploadMultipartWithReader(ctx context.Context, client *s3.Client, reader Re
 		}
 	}
 
-	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+	completeInput := &s3.CompleteMultipartUploadInput{
 		Bucket:          aws.String(bucket),
 		Key:             aws.String(key),
 		UploadId:        uploadID,
 		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
-	})
+	}
+	o.applyComplete(completeInput)
+
+	_, err = CompleteMultipartUpload(ctx, client, completeInput, size)
 	if err != nil {
+		AbortMultipartUpload(ctx, client, bucket, key, uploadID)
 		return fmt.Errorf("failed to complete multipart upload: %w", err)
 	}
 
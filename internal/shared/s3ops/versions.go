@@ -0,0 +1,78 @@
+package s3ops
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ObjectVersion is one entry in a versioned bucket's history: either a
+// stored version or a delete marker.
+type ObjectVersion struct {
+	Key            string
+	VersionID      string
+	Size           int64
+	LastModified   time.Time
+	ETag           string
+	StorageClass   string
+	IsLatest       bool
+	IsDeleteMarker bool
+}
+
+// ListObjectVersions returns every version and delete marker whose key
+// starts with prefix, grouped by key with the newest first.
+func ListObjectVersions(ctx context.Context, client *s3.Client, bucket, prefix string) ([]ObjectVersion, error) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	var versions []ObjectVersion
+	for {
+		page, err := client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list object versions: %w", err)
+		}
+
+		for _, v := range page.Versions {
+			versions = append(versions, ObjectVersion{
+				Key:          aws.ToString(v.Key),
+				VersionID:    aws.ToString(v.VersionId),
+				Size:         aws.ToInt64(v.Size),
+				LastModified: aws.ToTime(v.LastModified),
+				ETag:         aws.ToString(v.ETag),
+				StorageClass: string(v.StorageClass),
+				IsLatest:     aws.ToBool(v.IsLatest),
+			})
+		}
+		for _, d := range page.DeleteMarkers {
+			versions = append(versions, ObjectVersion{
+				Key:            aws.ToString(d.Key),
+				VersionID:      aws.ToString(d.VersionId),
+				LastModified:   aws.ToTime(d.LastModified),
+				IsLatest:       aws.ToBool(d.IsLatest),
+				IsDeleteMarker: true,
+			})
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		input.KeyMarker = page.NextKeyMarker
+		input.VersionIdMarker = page.NextVersionIdMarker
+	}
+
+	// Versions and delete markers come back in separate lists.
+	slices.SortStableFunc(versions, func(a, b ObjectVersion) int {
+		if c := cmp.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+		return b.LastModified.Compare(a.LastModified)
+	})
+	return versions, nil
+}
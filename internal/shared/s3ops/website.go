@@ -0,0 +1,207 @@
+package s3ops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// WebsiteConfiguration is a bucket's static website setup. Either
+// IndexDocument or RedirectAllRequestsTo must be set.
+type WebsiteConfiguration struct {
+	IndexDocument         string               `json:"IndexDocument,omitempty"`
+	ErrorDocument         string               `json:"ErrorDocument,omitempty"`
+	RedirectAllRequestsTo string               `json:"RedirectAllRequestsTo,omitempty"`
+	RoutingRules          []WebsiteRoutingRule `json:"RoutingRules,omitempty"`
+}
+
+// WebsiteRoutingRule redirects requests matching the condition fields
+// (KeyPrefixEquals, HttpErrorCodeReturnedEquals) according to the others.
+type WebsiteRoutingRule struct {
+	KeyPrefixEquals             string `json:"KeyPrefixEquals,omitempty"`
+	HttpErrorCodeReturnedEquals string `json:"HttpErrorCodeReturnedEquals,omitempty"`
+	HostName                    string `json:"HostName,omitempty"`
+	Protocol                    string `json:"Protocol,omitempty"`
+	HttpRedirectCode            string `json:"HttpRedirectCode,omitempty"`
+	ReplaceKeyPrefixWith        string `json:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith              string `json:"ReplaceKeyWith,omitempty"`
+}
+
+// ParseWebsiteConfig decodes a JSON website configuration, rejecting
+// unknown fields so a misspelt key isn't silently dropped.
+func ParseWebsiteConfig(data []byte) (*WebsiteConfiguration, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg WebsiteConfiguration
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse website config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// ValidateWebsiteConfig checks the constraints S3 enforces: an index
+// document (a plain suffix such as index.html) unless every request is
+// redirected, and routing rules only alongside an index document.
+func ValidateWebsiteConfig(cfg *WebsiteConfiguration) error {
+	if cfg.RedirectAllRequestsTo != "" {
+		if cfg.IndexDocument != "" || cfg.ErrorDocument != "" || len(cfg.RoutingRules) > 0 {
+			return errors.New("RedirectAllRequestsTo cannot be combined with an index document, error document or routing rules")
+		}
+		return nil
+	}
+	if cfg.IndexDocument == "" {
+		return errors.New("an index document is required to enable website hosting")
+	}
+	if strings.Contains(cfg.IndexDocument, "/") {
+		return fmt.Errorf("invalid index document %q: must be a suffix without /", cfg.IndexDocument)
+	}
+	for i, r := range cfg.RoutingRules {
+		if r.HostName == "" && r.Protocol == "" && r.HttpRedirectCode == "" && r.ReplaceKeyPrefixWith == "" && r.ReplaceKeyWith == "" {
+			return fmt.Errorf("routing rule %d has no redirect", i+1)
+		}
+		if r.ReplaceKeyPrefixWith != "" && r.ReplaceKeyWith != "" {
+			return fmt.Errorf("routing rule %d sets both ReplaceKeyPrefixWith and ReplaceKeyWith", i+1)
+		}
+		if r.Protocol != "" && r.Protocol != "http" && r.Protocol != "https" {
+			return fmt.Errorf("routing rule %d: invalid protocol %q: must be http or https", i+1, r.Protocol)
+		}
+	}
+	return nil
+}
+
+// GetBucketWebsite returns the bucket's website configuration, or nil if
+// website hosting is not enabled.
+func GetBucketWebsite(ctx context.Context, client *s3.Client, bucket string) (*WebsiteConfiguration, error) {
+	resp, err := client.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchWebsiteConfiguration" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bucket website: %w", err)
+	}
+
+	cfg := &WebsiteConfiguration{}
+	if resp.IndexDocument != nil {
+		cfg.IndexDocument = aws.ToString(resp.IndexDocument.Suffix)
+	}
+	if resp.ErrorDocument != nil {
+		cfg.ErrorDocument = aws.ToString(resp.ErrorDocument.Key)
+	}
+	if resp.RedirectAllRequestsTo != nil {
+		cfg.RedirectAllRequestsTo = aws.ToString(resp.RedirectAllRequestsTo.HostName)
+	}
+	for _, r := range resp.RoutingRules {
+		var rule WebsiteRoutingRule
+		if r.Condition != nil {
+			rule.KeyPrefixEquals = aws.ToString(r.Condition.KeyPrefixEquals)
+			rule.HttpErrorCodeReturnedEquals = aws.ToString(r.Condition.HttpErrorCodeReturnedEquals)
+		}
+		if r.Redirect != nil {
+			rule.HostName = aws.ToString(r.Redirect.HostName)
+			rule.Protocol = string(r.Redirect.Protocol)
+			rule.HttpRedirectCode = aws.ToString(r.Redirect.HttpRedirectCode)
+			rule.ReplaceKeyPrefixWith = aws.ToString(r.Redirect.ReplaceKeyPrefixWith)
+			rule.ReplaceKeyWith = aws.ToString(r.Redirect.ReplaceKeyWith)
+		}
+		cfg.RoutingRules = append(cfg.RoutingRules, rule)
+	}
+	return cfg, nil
+}
+
+func PutBucketWebsite(ctx context.Context, client *s3.Client, bucket string, cfg *WebsiteConfiguration) error {
+	if err := ValidateWebsiteConfig(cfg); err != nil {
+		return err
+	}
+
+	website := &types.WebsiteConfiguration{}
+	if cfg.RedirectAllRequestsTo != "" {
+		website.RedirectAllRequestsTo = &types.RedirectAllRequestsTo{HostName: aws.String(cfg.RedirectAllRequestsTo)}
+	}
+	if cfg.IndexDocument != "" {
+		website.IndexDocument = &types.IndexDocument{Suffix: aws.String(cfg.IndexDocument)}
+	}
+	if cfg.ErrorDocument != "" {
+		website.ErrorDocument = &types.ErrorDocument{Key: aws.String(cfg.ErrorDocument)}
+	}
+	for _, r := range cfg.RoutingRules {
+		rule := types.RoutingRule{
+			Redirect: &types.Redirect{
+				HostName:             optionalString(r.HostName),
+				Protocol:             types.Protocol(r.Protocol),
+				HttpRedirectCode:     optionalString(r.HttpRedirectCode),
+				ReplaceKeyPrefixWith: optionalString(r.ReplaceKeyPrefixWith),
+				ReplaceKeyWith:       optionalString(r.ReplaceKeyWith),
+			},
+		}
+		if r.KeyPrefixEquals != "" || r.HttpErrorCodeReturnedEquals != "" {
+			rule.Condition = &types.Condition{
+				KeyPrefixEquals:             optionalString(r.KeyPrefixEquals),
+				HttpErrorCodeReturnedEquals: optionalString(r.HttpErrorCodeReturnedEquals),
+			}
+		}
+		website.RoutingRules = append(website.RoutingRules, rule)
+	}
+
+	_, err := client.PutBucketWebsite(ctx, &s3.PutBucketWebsiteInput{
+		Bucket:               aws.String(bucket),
+		WebsiteConfiguration: website,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put bucket website: %w", err)
+	}
+	return nil
+}
+
+func DeleteBucketWebsite(ctx context.Context, client *s3.Client, bucket string) error {
+	_, err := client.DeleteBucketWebsite(ctx, &s3.DeleteBucketWebsiteInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket website: %w", err)
+	}
+	return nil
+}
+
+// ValidateRedirectLocation checks target is a form S3 accepts for
+// x-amz-website-redirect-location: a path starting with "/" or an absolute
+// http(s) URL.
+func ValidateRedirectLocation(target string) error {
+	if strings.HasPrefix(target, "/") || strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return nil
+	}
+	return fmt.Errorf("invalid redirect target %q: must start with /, http:// or https://", target)
+}
+
+// PutRedirect writes an empty object at key whose
+// x-amz-website-redirect-location points at target. The redirect only
+// takes effect through the bucket's website endpoint.
+func PutRedirect(ctx context.Context, client *s3.Client, bucket, key, target string, opts ...ObjectOption) error {
+	if err := ValidateRedirectLocation(target); err != nil {
+		return err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:                  aws.String(bucket),
+		Key:                     aws.String(key),
+		Body:                    strings.NewReader(""),
+		ContentLength:           aws.Int64(0),
+		WebsiteRedirectLocation: aws.String(target),
+	}
+	newObjectOptions(opts).applyPut(input)
+
+	if _, err := client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to put redirect: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Non-terminal progress is logged every ProgressLogInterval or every
+// ProgressLogStep percent, whichever comes first.
+const (
+	ProgressLogInterval = 5 * time.Second
+	ProgressLogStep     = 10.0
+)
+
+// IsTerminal reports whether w writes to a terminal. Progress is redrawn in
+// place only then; in CI logs the cursor movement and carriage returns show
+// up as garbage.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// ProgressLog throttles plain progress lines for non-terminal output. The
+// zero value is ready to use.
+type ProgressLog struct {
+	started bool
+	last    time.Time
+	lastPct float64
+}
+
+// Due reports whether a line for pct should be written now, and if so
+// records it as the last one written.
+func (l *ProgressLog) Due(pct float64) bool {
+	now := time.Now()
+	due := !l.started ||
+		now.Sub(l.last) >= ProgressLogInterval ||
+		int(pct/ProgressLogStep) > int(l.lastPct/ProgressLogStep)
+	if due {
+		l.started, l.last, l.lastPct = true, now, pct
+	}
+	return due
+}
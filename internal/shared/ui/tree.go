@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+)
+
+// TreeEntry writes one line of an indented tree, as printed by the tree
+// command, and returns the indent for the entry's children. indent is the
+// prefix its parent was given; last marks the final entry at that level.
+func TreeEntry(w io.Writer, indent string, last bool, label string) string {
+	connector, childIndent := "├── ", indent+"│   "
+	if last {
+		connector, childIndent = "└── ", indent+"    "
+	}
+	fmt.Fprintf(w, "%s%s%s\n", indent, connector, label)
+	return childIndent
+}
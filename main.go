@@ -5,10 +5,33 @@ import (
 	"os"
 	"strings"
 
+	"s3-client/internal/cmd/acl"
+	"s3-client/internal/cmd/batch"
+	"s3-client/internal/cmd/buckettag"
+	"s3-client/internal/cmd/cat"
 	"s3-client/internal/cmd/connect"
+	"s3-client/internal/cmd/cp"
 	"s3-client/internal/cmd/download"
+	"s3-client/internal/cmd/du"
+	"s3-client/internal/cmd/encryption"
+	"s3-client/internal/cmd/exists"
+	"s3-client/internal/cmd/lifecycle"
+	"s3-client/internal/cmd/ls"
+	"s3-client/internal/cmd/multipart"
+	"s3-client/internal/cmd/objectlock"
+	"s3-client/internal/cmd/ping"
+	"s3-client/internal/cmd/policy"
+	"s3-client/internal/cmd/redirect"
+	"s3-client/internal/cmd/restore"
 	"s3-client/internal/cmd/setcors"
+	"s3-client/internal/cmd/stat"
+	"s3-client/internal/cmd/tail"
+	"s3-client/internal/cmd/tree"
+	"s3-client/internal/cmd/updatemetadata"
 	"s3-client/internal/cmd/upload"
+	"s3-client/internal/cmd/versions"
+	"s3-client/internal/cmd/website"
+	"s3-client/internal/cmd/whoami"
 )
 
 const binaryName = "s3-client"
@@ -29,12 +52,81 @@ func main() {
 	case "upload", "up":
 		code := upload.Run(args)
 		os.Exit(code)
+	case "ls", "list":
+		code := ls.Run(args)
+		os.Exit(code)
+	case "cat":
+		code := cat.Run(args)
+		os.Exit(code)
+	case "tail":
+		code := tail.Run(args)
+		os.Exit(code)
 	case "connect":
 		code := connect.Run(args)
 		os.Exit(code)
 	case "set-cors", "cors":
 		code := setcors.Run(args)
 		os.Exit(code)
+	case "lifecycle":
+		code := lifecycle.Run(args)
+		os.Exit(code)
+	case "policy":
+		code := policy.Run(args)
+		os.Exit(code)
+	case "bucket-tag":
+		code := buckettag.Run(args)
+		os.Exit(code)
+	case "website":
+		code := website.Run(args)
+		os.Exit(code)
+	case "acl":
+		code := acl.Run(args)
+		os.Exit(code)
+	case "exists", "head":
+		code := exists.Run(args)
+		os.Exit(code)
+	case "stat":
+		code := stat.Run(args)
+		os.Exit(code)
+	case "object-lock":
+		code := objectlock.Run(args)
+		os.Exit(code)
+	case "restore":
+		code := restore.Run(args)
+		os.Exit(code)
+	case "du":
+		code := du.Run(args)
+		os.Exit(code)
+	case "tree":
+		code := tree.Run(args)
+		os.Exit(code)
+	case "cp", "copy":
+		code := cp.Run(args)
+		os.Exit(code)
+	case "multipart":
+		code := multipart.Run(args)
+		os.Exit(code)
+	case "batch":
+		code := batch.Run(args)
+		os.Exit(code)
+	case "versions":
+		code := versions.Run(args)
+		os.Exit(code)
+	case "redirect":
+		code := redirect.Run(args)
+		os.Exit(code)
+	case "update-metadata":
+		code := updatemetadata.Run(args)
+		os.Exit(code)
+	case "ping":
+		code := ping.Run(args)
+		os.Exit(code)
+	case "whoami":
+		code := whoami.Run(args)
+		os.Exit(code)
+	case "encryption":
+		code := encryption.Run(args)
+		os.Exit(code)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown subcommand: %q\n\n", sub)
 		printUsage()
@@ -47,8 +139,31 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "Commands:")
 	fmt.Fprintln(os.Stderr, "  download, dl    Download an object from S3 (parallel chunked)")
 	fmt.Fprintln(os.Stderr, "  upload, up     Upload a file or directory to S3")
+	fmt.Fprintln(os.Stderr, "  ls, list       List buckets or objects under a prefix (-json for NDJSON)")
+	fmt.Fprintln(os.Stderr, "  cat            Write an object's contents to stdout (-range for part of it)")
+	fmt.Fprintln(os.Stderr, "  tail           Follow an object that grows over time, like tail -f")
 	fmt.Fprintln(os.Stderr, "  connect        Open interactive TUI to browse S3")
 	fmt.Fprintln(os.Stderr, "  set-cors, cors Configure CORS for a bucket")
+	fmt.Fprintln(os.Stderr, "  lifecycle      Manage bucket lifecycle rules")
+	fmt.Fprintln(os.Stderr, "  policy         Manage the bucket policy")
+	fmt.Fprintln(os.Stderr, "  encryption     Manage bucket default encryption")
+	fmt.Fprintln(os.Stderr, "  website        Manage bucket static website hosting")
+	fmt.Fprintln(os.Stderr, "  bucket-tag     Show, set, or delete bucket tags")
+	fmt.Fprintln(os.Stderr, "  acl            Show or set the ACL of an object")
+	fmt.Fprintln(os.Stderr, "  exists, head   Check whether an object or bucket exists")
+	fmt.Fprintln(os.Stderr, "  stat           Show an object's metadata, checksum and multipart part layout")
+	fmt.Fprintln(os.Stderr, "  object-lock    Show or set Object Lock retention and legal hold on an object")
+	fmt.Fprintln(os.Stderr, "  restore        Restore an archived object from Glacier")
+	fmt.Fprintln(os.Stderr, "  du             Show total size and object count under a prefix")
+	fmt.Fprintln(os.Stderr, "  tree           Show the directories and objects under a prefix as a tree")
+	fmt.Fprintln(os.Stderr, "  cp, copy       Copy an object server-side, optionally changing metadata or storage class")
+	fmt.Fprintln(os.Stderr, "  multipart      List or abort incomplete multipart uploads")
+	fmt.Fprintln(os.Stderr, "  batch          Run download/upload/delete/copy operations from a JSON-lines file")
+	fmt.Fprintln(os.Stderr, "  versions       List the versions of a key or prefix, or prune old ones with -keep")
+	fmt.Fprintln(os.Stderr, "  update-metadata Change an object's Content-Type, Cache-Control or user metadata in place")
+	fmt.Fprintln(os.Stderr, "  redirect       Create an empty object that redirects to another page or URL")
+	fmt.Fprintln(os.Stderr, "  ping           Check endpoint, credentials and region with a ListBuckets call")
+	fmt.Fprintln(os.Stderr, "  whoami         Show the account and identity in use, or -list-profiles")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintf(os.Stderr, "Use \"%s <command> -h\" for command-specific help.\n", binaryName)
 }